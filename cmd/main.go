@@ -4,7 +4,9 @@ import (
 	"context"
 	"log"
 	"os"
+	"university/internal/blob"
 	"university/internal/handler"
+	"university/internal/mail"
 	"university/internal/server"
 	"university/internal/service"
 	"university/internal/storage"
@@ -21,6 +23,16 @@ import (
 // @host            uni-server-29pn.onrender.com
 // @schemes         https
 // @BasePath        /
+//
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT access token as "Bearer <token>", verifiable with /.well-known/jwks.json
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 Scoped API key for service integrations
 func main() {
 	var err error
 
@@ -55,7 +67,20 @@ func main() {
 
 	repo.InitDB()
 
-	svc := service.NewService(repo)
+	mailer, err := mail.NewSenderFromEnv(repo)
+	if err != nil {
+		logger.Fatal("Error configuring mail sender: ", err)
+	}
+
+	blobs, err := blob.NewStoreFromEnv()
+	if err != nil {
+		logger.Fatal("Error configuring blob store: ", err)
+	}
+
+	svc, err := service.NewService(repo, mailer, blobs, logger)
+	if err != nil {
+		logger.Fatal("Error configuring service: ", err)
+	}
 	hnd := handler.NewHandler(svc)
 	srv := server.NewServer(hnd)
 
@@ -1,3 +1,18 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- search_key folds text to lower-case Latin so names entered in Cyrillic
+-- (Russian and Kazakh letters) and in Latin match each other
+CREATE OR REPLACE FUNCTION search_key(value TEXT) RETURNS TEXT AS $$
+    SELECT translate(
+        replace(replace(replace(replace(replace(replace(replace(replace(replace(
+            lower(COALESCE(value, '')),
+            'щ', 'shch'), 'ж', 'zh'), 'х', 'kh'), 'ц', 'ts'), 'ч', 'ch'),
+            'ш', 'sh'), 'ю', 'yu'), 'я', 'ya'), 'ё', 'yo'),
+        'абвгдезийклмнопрстуфыэәғқңөұүһіъь',
+        'abvgdeziyklmnoprstufyeagknouuhi'
+    )
+$$ LANGUAGE SQL IMMUTABLE;
+
 CREATE TABLE faculties (
     id SERIAL PRIMARY KEY,
     name VARCHAR(50) NOT NULL
@@ -14,7 +29,8 @@ CREATE TABLE users (
     email VARCHAR(100) UNIQUE NOT NULL,
     password_hash TEXT NOT NULL,
     is_active BOOLEAN DEFAULT TRUE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    email_verified_at TIMESTAMP
 );
 
 CREATE TABLE roles (
@@ -35,7 +51,10 @@ CREATE TABLE students (
     gender VARCHAR(10),
     birth_date DATE,
     group_id INT REFERENCES groups(id),
-    user_id INT UNIQUE REFERENCES users(id) ON DELETE SET NULL
+    user_id INT UNIQUE REFERENCES users(id) ON DELETE SET NULL,
+    archived_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'enrolled'
+        CHECK (status IN ('enrolled', 'on_leave', 'graduated', 'expelled', 'transferred'))
 );
 
 CREATE TABLE staff (
@@ -76,20 +95,239 @@ CREATE TABLE grades (
     graded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    family_id VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by INT REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens(family_id);
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE mail_outbox (
+    id SERIAL PRIMARY KEY,
+    sender VARCHAR(100) NOT NULL,
+    recipient VARCHAR(100) NOT NULL,
+    subject VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE email_verification_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE login_attempts (
+    key VARCHAR(150) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP,
+    locked_until TIMESTAMP
+);
+
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE oidc_login_states (
+    state TEXT PRIMARY KEY,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP,
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+
+CREATE TABLE user_mfa (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMP,
+    last_used_step BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+
+CREATE TABLE mfa_challenges (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id VARCHAR(32) UNIQUE NOT NULL,
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id);
+
+CREATE TABLE impersonations (
+    id SERIAL PRIMARY KEY,
+    actor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+CREATE TABLE impersonation_requests (
+    id SERIAL PRIMARY KEY,
+    impersonation_id INT NOT NULL REFERENCES impersonations(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status INT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_impersonation_requests_impersonation ON impersonation_requests(impersonation_id);
+
+CREATE TABLE teaching_assignments (
+    id SERIAL PRIMARY KEY,
+    staff_id INT NOT NULL REFERENCES staff(id) ON DELETE CASCADE,
+    group_id INT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    subject_id INT NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (staff_id, group_id, subject_id)
+);
+
+CREATE TABLE invitations (
+    id SERIAL PRIMARY KEY,
+    code_hash VARCHAR(64) UNIQUE NOT NULL,
+    student_id INT REFERENCES students(id) ON DELETE CASCADE,
+    staff_id INT REFERENCES staff(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    email VARCHAR(255),
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    used_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((student_id IS NULL) <> (staff_id IS NULL))
+);
+
+CREATE INDEX idx_students_name_search ON students USING GIN (search_key(first_name || ' ' || last_name) gin_trgm_ops);
+CREATE INDEX idx_staff_name_search ON staff USING GIN (search_key(first_name || ' ' || last_name) gin_trgm_ops);
+CREATE INDEX idx_users_email_search ON users USING GIN (search_key(email) gin_trgm_ops);
+CREATE INDEX idx_groups_name_search ON groups USING GIN (search_key(name) gin_trgm_ops);
+CREATE INDEX idx_subjects_name_search ON subjects USING GIN (search_key(name) gin_trgm_ops);
+
+CREATE TABLE student_group_history (
+    id SERIAL PRIMARY KEY,
+    student_id INT NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    group_id INT REFERENCES groups(id),
+    valid_from DATE,
+    valid_to DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_student_group_history_student ON student_group_history(student_id, valid_from);
+CREATE INDEX idx_student_group_history_group ON student_group_history(group_id);
+
+CREATE TABLE student_status_changes (
+    id SERIAL PRIMARY KEY,
+    student_id INT NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_student_status_changes_student ON student_status_changes(student_id);
+
+CREATE TABLE documents (
+    id SERIAL PRIMARY KEY,
+    student_id INT REFERENCES students(id) ON DELETE CASCADE,
+    staff_id INT REFERENCES staff(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    uploaded_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((student_id IS NULL) <> (staff_id IS NULL))
+);
+
+CREATE INDEX idx_documents_student ON documents(student_id);
+CREATE INDEX idx_documents_staff ON documents(staff_id);
+
 INSERT INTO roles (name) VALUES
 ('ADMIN'),
 ('TEACHER'),
 ('STUDENT');
 
-INSERT INTO users (email, password_hash) VALUES
-('admin@university.kz', 'hashed_admin_password'),
-('teacher1@university.kz', 'hashed_teacher1_password'),
-('teacher2@university.kz', 'hashed_teacher2_password'),
-('anna@university.kz', 'hashed_anna_password'),
-('maria@university.kz', 'hashed_maria_password'),
-('alex@university.kz', 'hashed_alex_password'),
-('elena@university.kz', 'hashed_elena_password'),
-('ivan@university.kz', 'hashed_ivan_password');
+INSERT INTO users (email, password_hash, email_verified_at) VALUES
+('admin@university.kz', 'hashed_admin_password', CURRENT_TIMESTAMP),
+('teacher1@university.kz', 'hashed_teacher1_password', CURRENT_TIMESTAMP),
+('teacher2@university.kz', 'hashed_teacher2_password', CURRENT_TIMESTAMP),
+('anna@university.kz', 'hashed_anna_password', CURRENT_TIMESTAMP),
+('maria@university.kz', 'hashed_maria_password', CURRENT_TIMESTAMP),
+('alex@university.kz', 'hashed_alex_password', CURRENT_TIMESTAMP),
+('elena@university.kz', 'hashed_elena_password', CURRENT_TIMESTAMP),
+('ivan@university.kz', 'hashed_ivan_password', CURRENT_TIMESTAMP);
 
 INSERT INTO user_roles (user_id, role_id) VALUES
 (1, 1),
@@ -122,6 +360,10 @@ INSERT INTO students (user_id, first_name, last_name, gender, birth_date, group_
 (7, 'Elena', 'Sidorova', 'Female', '2004-01-15', 4),
 (8, 'Ivan', 'Kuznetsov', 'Male', '2002-07-08', 5);
 
+-- Seeded students have been in their groups since before history was kept
+INSERT INTO student_group_history (student_id, group_id)
+SELECT id, group_id FROM students;
+
 INSERT INTO staff (user_id, first_name, last_name, faculty_id, position) VALUES
 (1, 'System', 'Admin', NULL, 'Administrator'),
 (2, 'John', 'Doe', 1, 'Lecturer'),
@@ -141,6 +383,13 @@ INSERT INTO schedule (faculty_id, group_id, subject_id, class_time) VALUES
 (1, 2, 4, '14:45–16:15'),
 (3, 5, 5, '16:30–18:00');
 
+-- Teachers teach the scheduled groups and subjects of their faculty
+INSERT INTO teaching_assignments (staff_id, group_id, subject_id) VALUES
+(2, 1, 3),
+(2, 2, 4),
+(3, 3, 1),
+(3, 4, 2);
+
 INSERT INTO attendance (student_id, subject_id, visit_day, visited) VALUES
 (1, 1, '2026-01-06', true),
 (1, 3, '2026-01-07', true),
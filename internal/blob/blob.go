@@ -0,0 +1,56 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNotFound is returned when nothing is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps uploaded files under slash-separated keys
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any previous blob
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key; the caller closes it
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the blob under key; deleting a missing blob is not an error
+	Delete(key string) error
+}
+
+// NewStoreFromEnv builds the store selected by BLOB_DRIVER ("local" or "s3").
+// The local driver is the default and keeps files under BLOB_LOCAL_DIR; the
+// s3 driver talks to any S3-compatible service, such as MinIO.
+func NewStoreFromEnv() (Store, error) {
+	switch driver := os.Getenv("BLOB_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("BLOB_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStore(dir)
+	case "s3":
+		cfg := S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") != "false",
+		}
+		if cfg.Region == "" {
+			cfg.Region = "us-east-1"
+		}
+		if cfg.Endpoint == "" || cfg.Bucket == "" {
+			return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required when BLOB_DRIVER is s3")
+		}
+		if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, errors.New("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required when BLOB_DRIVER is s3")
+		}
+		return NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown BLOB_DRIVER: %s", driver)
+	}
+}
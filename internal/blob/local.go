@@ -0,0 +1,86 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore keeps blobs as files under a directory
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore creates a LocalStore, creating dir if needed
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// path maps a key to a file inside the store's directory
+func (s *LocalStore) path(key string) (string, error) {
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid blob key: %s", key)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// Put writes the blob to a temporary file and renames it into place, so
+// readers never see a partial file
+func (s *LocalStore) Put(key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("blob size mismatch: wrote %d of %d bytes", written, size)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStore) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s *LocalStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorePathRejectsEscapes(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{
+		"../outside",
+		"students/../../outside",
+		"..",
+		"/etc/passwd",
+		"",
+	} {
+		if _, err := store.path(key); err == nil {
+			t.Errorf("path(%q) was accepted", key)
+		}
+		if err := store.Put(key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put(%q) was accepted", key)
+		}
+	}
+
+	for _, key := range []string{"students/1/file.pdf", "a..b", "students/1/../2/file.pdf"} {
+		path, err := store.path(key)
+		if err != nil {
+			t.Errorf("path(%q) = %v", key, err)
+			continue
+		}
+		if !strings.HasPrefix(path, store.dir+string(filepath.Separator)) {
+			t.Errorf("path(%q) = %s, outside %s", key, path, store.dir)
+		}
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put("students/1/a.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	body, err := store.Get("students/1/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(body)
+	body.Close()
+	if string(got) != "hello" {
+		t.Errorf("Get returned %q, want %q", got, "hello")
+	}
+
+	if err := store.Put("students/1/b.txt", strings.NewReader("hello"), 4, "text/plain"); err == nil {
+		t.Error("Put accepted a size mismatch")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "students", "1", "b.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a failed Put left a file behind")
+	}
+
+	if err := store.Delete("students/1/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("students/1/a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("students/1/a.txt"); err != nil {
+		t.Errorf("Delete of a missing blob = %v, want nil", err)
+	}
+}
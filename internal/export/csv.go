@@ -0,0 +1,36 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// CSVWriter writes rows as comma-separated values
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter creates a CSVWriter on w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write writes one row. Values that a spreadsheet would evaluate as a formula
+// are prefixed with a quote so they are shown as text.
+func (c *CSVWriter) Write(record []string) error {
+	escaped := make([]string, len(record))
+	for i, value := range record {
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			value = "'" + value
+		}
+		escaped[i] = value
+	}
+	return c.w.Write(escaped)
+}
+
+// Close flushes buffered rows
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,40 @@
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+// RowWriter writes a table one row at a time. Close must be called after the
+// last row to finish the file.
+type RowWriter interface {
+	Write(record []string) error
+	Close() error
+}
+
+// Formats supported by New
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// New creates a RowWriter for format on w. sheetName names the sheet of XLSX
+// workbooks.
+func New(w io.Writer, format, sheetName string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheetName), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// xlsxParts are the fixed parts of a workbook with a single sheet
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xmlHeader +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xmlHeader +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", xmlHeader +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// XLSXWriter writes rows to an Excel workbook with a single sheet of text
+// cells. The sheet is compressed straight into w as rows arrive, so memory use
+// does not grow with the number of rows.
+type XLSXWriter struct {
+	zip       *zip.Writer
+	sheet     *bufio.Writer
+	sheetName string
+	started   bool
+}
+
+// NewXLSXWriter creates an XLSXWriter on w whose sheet is named sheetName
+func NewXLSXWriter(w io.Writer, sheetName string) *XLSXWriter {
+	return &XLSXWriter{zip: zip.NewWriter(w), sheetName: sheetName}
+}
+
+// start writes the fixed parts and opens the sheet. Nothing reaches w before
+// the first row, so a failed export can still be answered with an error.
+func (x *XLSXWriter) start() error {
+	x.started = true
+
+	for _, part := range xlsxParts {
+		if err := x.writePart(part.name, part.content); err != nil {
+			return err
+		}
+	}
+
+	workbook := xmlHeader +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + escapeXML(x.sheetName) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := x.writePart("xl/workbook.xml", workbook); err != nil {
+		return err
+	}
+
+	sheet, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(sheet)
+	_, err = x.sheet.WriteString(xmlHeader +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+func (x *XLSXWriter) writePart(name, content string) error {
+	part, err := x.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+// Write appends one row of text cells
+func (x *XLSXWriter) Write(record []string) error {
+	if !x.started {
+		if err := x.start(); err != nil {
+			return err
+		}
+	}
+
+	x.sheet.WriteString("<row>")
+	for _, value := range record {
+		x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		x.sheet.WriteString(escapeXML(value))
+		x.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+// Close finishes the sheet and the zip archive
+func (x *XLSXWriter) Close() error {
+	if !x.started {
+		if err := x.start(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := x.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// escapeXML escapes text for an element or attribute, replacing characters
+// XML cannot hold
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/service"
+)
+
+// UnlockUser godoc
+// @Summary      Unlock a user account
+// @Description  Clears failed login attempts and any temporary lockout for the account.
+// @Tags         admin
+// @Param        id   path  int  true  "User ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/unlock [post]
+func (h *Handler) UnlockUser(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	if err := h.service.UnlockUser(userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListUsers godoc
+// @Summary      List users
+// @Tags         admin
+// @Param        q       query  string  false  "Email substring"
+// @Param        role    query  string  false  "Role name (ADMIN, TEACHER, STUDENT)"
+// @Param        active  query  bool    false  "Filter by active flag"
+// @Param        limit   query  int     false  "Page size (default 50, max 500)"
+// @Param        offset  query  int     false  "Number of rows to skip"
+// @Param        sort    query  string  false  "id, email or created_at; prefix with - to sort descending"
+// @Success      200     {object}  model.Page[model.UserResponse]
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users [get]
+func (h *Handler) ListUsers(c echo.Context) error {
+	_, params, err := listParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	filter := model.UserFilter{
+		Query: c.QueryParam("q"),
+		Role:  c.QueryParam("role"),
+	}
+	if active := c.QueryParam("active"); active != "" {
+		value, err := strconv.ParseBool(active)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid active filter"})
+		}
+		filter.Active = &value
+	}
+
+	users, err := h.service.ListUsers(&filter, params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAdminRequest) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return listError(c, err)
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+// UpdateUserStatus godoc
+// @Summary      Activate or deactivate a user
+// @Tags         admin
+// @Accept       json
+// @Param        id    path  int                            true  "User ID"
+// @Param        body  body  model.UpdateUserStatusRequest  true  "New status"
+// @Success      200   {object}  model.UserResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/status [patch]
+func (h *Handler) UpdateUserStatus(c echo.Context) error {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	var req model.UpdateUserStatusRequest
+	if err := c.Bind(&req); err != nil || req.IsActive == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "is_active is required"})
+	}
+
+	user, err := h.service.SetUserActive(actorID, userID, *req.IsActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		if errors.Is(err, service.ErrInvalidAdminRequest) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+// GrantUserRole godoc
+// @Summary      Grant a role to a user
+// @Tags         admin
+// @Accept       json
+// @Param        id    path  int                true  "User ID"
+// @Param        body  body  model.RoleRequest  true  "Role to grant"
+// @Success      200   {object}  model.UserResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/roles [post]
+func (h *Handler) GrantUserRole(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	var req model.RoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	user, err := h.service.GrantRole(userID, req.Role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		if errors.Is(err, service.ErrInvalidAdminRequest) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+// RevokeUserRole godoc
+// @Summary      Revoke a role from a user
+// @Tags         admin
+// @Param        id    path  int     true  "User ID"
+// @Param        role  path  string  true  "Role name"
+// @Success      200   {object}  model.UserResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/roles/{role} [delete]
+func (h *Handler) RevokeUserRole(c echo.Context) error {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	user, err := h.service.RevokeRole(actorID, userID, c.Param("role"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		if errors.Is(err, service.ErrInvalidAdminRequest) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+// ForcePasswordReset godoc
+// @Summary      Force a password reset
+// @Description  Invalidates the user's password, signs them out everywhere and emails a reset link.
+// @Tags         admin
+// @Param        id   path  int  true  "User ID"
+// @Success      202  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/password-reset [post]
+func (h *Handler) ForcePasswordReset(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	if err := h.service.ForcePasswordReset(userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "password reset email sent"})
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  Issues a scoped API key for a service integration. The key is only returned once.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      model.CreateAPIKeyRequest  true  "Key name, scopes and lifetime"
+// @Success      201   {object}  model.CreateAPIKeyResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c echo.Context) error {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	var req model.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	resp, err := h.service.CreateAPIKey(actorID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKeyRequest) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   model.APIKey
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/api-keys [get]
+func (h *Handler) ListAPIKeys(c echo.Context) error {
+	keys, err := h.service.ListAPIKeys()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Tags         admin
+// @Param        id   path  int  true  "API key ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid API key id"})
+	}
+
+	if err := h.service.RevokeAPIKey(id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/storage"
+)
+
+// RestoreStudent godoc
+// @Summary      Restore an archived student
+// @Tags         students
+// @Param        id   path      string  true  "Student ID"
+// @Success      200  {object}  model.StudentResponse
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /students/{id}/restore [post]
+func (h *Handler) RestoreStudent(c echo.Context) error {
+	student, err := h.service.RestoreStudent(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "student not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, student)
+}
+
+// PurgeStudent godoc
+// @Summary      Permanently delete an archived student
+// @Description  Deletes the student together with their attendance, grades and documents. The student must be archived first.
+// @Tags         admin
+// @Param        id   path      string  true  "Student ID"
+// @Success      200  {object}  model.StudentPurgeResult
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/students/{id} [delete]
+func (h *Handler) PurgeStudent(c echo.Context) error {
+	result, err := h.service.PurgeStudent(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "student not found"})
+		}
+		if errors.Is(err, storage.ErrNotArchived) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "archive the student before purging"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
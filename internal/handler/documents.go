@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/blob"
+	"university/internal/model"
+	"university/internal/service"
+)
+
+// UploadStudentDocument godoc
+// @Summary      Upload a document for a student
+// @Description  Admins may upload for any student, students for themselves. Accepted types depend on kind: photo takes JPEG, PNG or WebP; medical_certificate and other take PDF, JPEG or PNG; contract takes PDF. Types are detected from the file content.
+// @Tags         documents
+// @Accept       multipart/form-data
+// @Param        id    path      int     true  "Student ID"
+// @Param        kind  formData  string  true  "photo, medical_certificate, contract or other"
+// @Param        file  formData  file    true  "Document file"
+// @Success      201   {object}  model.Document
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      413   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /students/{id}/documents [post]
+func (h *Handler) UploadStudentDocument(c echo.Context) error {
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid student id"})
+	}
+	return h.uploadDocument(c, &model.UploadDocumentRequest{StudentID: studentID})
+}
+
+// UploadStaffDocument godoc
+// @Summary      Upload a document for a staff member
+// @Description  Admins may upload for any staff member, staff for themselves. Accepted types are as for student documents.
+// @Tags         documents
+// @Accept       multipart/form-data
+// @Param        id    path      int     true  "Staff ID"
+// @Param        kind  formData  string  true  "photo, medical_certificate, contract or other"
+// @Param        file  formData  file    true  "Document file"
+// @Success      201   {object}  model.Document
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      413   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /staff/{id}/documents [post]
+func (h *Handler) UploadStaffDocument(c echo.Context) error {
+	staffID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid staff id"})
+	}
+	return h.uploadDocument(c, &model.UploadDocumentRequest{StaffID: staffID})
+}
+
+// uploadDocument reads the multipart upload of a student or staff document
+func (h *Handler) uploadDocument(c echo.Context, req *model.UploadDocumentRequest) error {
+	// Leave room for the form fields and multipart framing around the file
+	maxSize := h.service.DocumentMaxSize()
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxSize+64<<10)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+				"error": "file is larger than " + strconv.FormatInt(maxSize, 10) + " bytes",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "failed to read file"})
+	}
+	defer file.Close()
+
+	req.Kind = c.FormValue("kind")
+	req.FileName = header.Filename
+	req.Size = header.Size
+
+	doc, err := h.service.UploadDocument(currentActor(c), req, file)
+	if err != nil {
+		return documentError(c, err)
+	}
+	return c.JSON(http.StatusCreated, doc)
+}
+
+// ListStudentDocuments godoc
+// @Summary      List a student's documents
+// @Tags         documents
+// @Param        id   path      int  true  "Student ID"
+// @Success      200  {array}   model.Document
+// @Failure      403  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /students/{id}/documents [get]
+func (h *Handler) ListStudentDocuments(c echo.Context) error {
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid student id"})
+	}
+
+	documents, err := h.service.ListDocuments(currentActor(c), studentID, 0)
+	if err != nil {
+		return documentError(c, err)
+	}
+	return c.JSON(http.StatusOK, documents)
+}
+
+// ListStaffDocuments godoc
+// @Summary      List a staff member's documents
+// @Tags         documents
+// @Param        id   path      int  true  "Staff ID"
+// @Success      200  {array}   model.Document
+// @Failure      403  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /staff/{id}/documents [get]
+func (h *Handler) ListStaffDocuments(c echo.Context) error {
+	staffID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid staff id"})
+	}
+
+	documents, err := h.service.ListDocuments(currentActor(c), 0, staffID)
+	if err != nil {
+		return documentError(c, err)
+	}
+	return c.JSON(http.StatusOK, documents)
+}
+
+// CreateDocumentLink godoc
+// @Summary      Get a download link for a document
+// @Description  The link works without authentication until it expires, so it can be opened directly by a browser.
+// @Tags         documents
+// @Param        id   path      int  true  "Document ID"
+// @Success      200  {object}  model.DocumentLink
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /documents/{id}/link [get]
+func (h *Handler) CreateDocumentLink(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid document id"})
+	}
+
+	link, err := h.service.CreateDocumentLink(currentActor(c), id)
+	if err != nil {
+		return documentError(c, err)
+	}
+	link.URL = c.Scheme() + "://" + c.Request().Host + link.URL
+	return c.JSON(http.StatusOK, link)
+}
+
+// DownloadDocument godoc
+// @Summary      Download a document through a signed link
+// @Tags         documents
+// @Produce      octet-stream
+// @Param        id         path   int     true  "Document ID"
+// @Param        expires    query  int     true  "Link expiry, Unix seconds"
+// @Param        signature  query  string  true  "Link signature"
+// @Success      200  {file}    file
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /documents/{id}/download [get]
+func (h *Handler) DownloadDocument(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid document id"})
+	}
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": service.ErrInvalidLink.Error()})
+	}
+
+	doc, file, err := h.service.OpenDocument(id, expires, c.QueryParam("signature"))
+	if err != nil {
+		return documentError(c, err)
+	}
+	defer file.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(doc.Size, 10))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "private, no-store")
+	return c.Stream(http.StatusOK, doc.ContentType, file)
+}
+
+// DeleteDocument godoc
+// @Summary      Delete a document
+// @Description  Admins may delete any document; other users only documents they uploaded to their own record.
+// @Tags         documents
+// @Param        id   path  int  true  "Document ID"
+// @Success      204
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /documents/{id} [delete]
+func (h *Handler) DeleteDocument(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid document id"})
+	}
+
+	if err := h.service.DeleteDocument(currentActor(c), id); err != nil {
+		return documentError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// documentError responds to a failed document operation
+func documentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidDocument):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrDocumentAccess), errors.Is(err, service.ErrInvalidLink):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, pgx.ErrNoRows):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	case errors.Is(err, blob.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "document file is missing"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
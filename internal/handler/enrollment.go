@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/service"
+	"university/internal/storage"
+)
+
+// ChangeStudentStatus godoc
+// @Summary      Change a student's enrollment status
+// @Description  Allowed transitions: enrolled to on_leave, graduated, expelled or transferred; on_leave to enrolled, expelled or transferred; expelled to enrolled. Graduated and transferred are final.
+// @Tags         students
+// @Accept       json
+// @Param        id    path      string                            true  "Student ID"
+// @Param        body  body      model.ChangeStudentStatusRequest  true  "New status and reason"
+// @Success      200   {object}  model.StudentStatusChange
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /students/{id}/status [post]
+func (h *Handler) ChangeStudentStatus(c echo.Context) error {
+	var req model.ChangeStudentStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	change, err := h.service.ChangeStudentStatus(actorID, c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidStatusChange):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, pgx.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "student not found"})
+		case errors.Is(err, storage.ErrInvalidTransition):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, change)
+}
+
+// GetStudentStatusChanges godoc
+// @Summary      Get a student's enrollment status changes
+// @Tags         students
+// @Param        id   path      string  true  "Student ID"
+// @Success      200  {array}   model.StudentStatusChange
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /students/{id}/status-history [get]
+func (h *Handler) GetStudentStatusChanges(c echo.Context) error {
+	changes, err := h.service.GetStudentStatusChanges(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "student not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, changes)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"university/internal/export"
+	"university/internal/service"
+)
+
+// ExportStudents godoc
+// @Summary      Export students to CSV or XLSX
+// @Description  Streams every student matching the filters, without paging.
+// @Tags         students
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format      query  string  false  "csv (default) or xlsx"
+// @Param        columns     query  string  false  "Comma-separated columns in output order: id, first_name, last_name, gender, birth_date, group, faculty, email, status (default all)"
+// @Param        group_id    query  int     false  "Only students of this group"
+// @Param        faculty_id  query  int     false  "Only students of this faculty"
+// @Param        archived    query  bool    false  "Export archived students instead of current ones"
+// @Param        status      query  string  false  "Enrollment status, or all (default enrolled)"
+// @Param        sort        query  string  false  "id, first_name, last_name or group; prefix with - to sort descending"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /students/export [get]
+func (h *Handler) ExportStudents(c echo.Context) error {
+	filter, params, err := listParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	var columns []string
+	if raw := c.QueryParam("columns"); raw != "" {
+		columns = strings.Split(raw, ",")
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, export.ContentType(format))
+	header.Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="students-%s.%s"`, time.Now().Format(time.DateOnly), format))
+
+	err = h.service.ExportStudents(c.Response(), format, columns, filter, params.Sort)
+	if err == nil || c.Response().Committed {
+		// Once rows are streaming the status is sent and the error can only be logged
+		return err
+	}
+
+	header.Del(echo.HeaderContentDisposition)
+	if errors.Is(err, service.ErrInvalidExport) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return listError(c, err)
+}
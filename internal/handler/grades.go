@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/service"
+)
+
+// CreateGrade godoc
+// @Summary      Post a grade
+// @Description  Teachers may only grade students of groups they teach the subject to within their faculty.
+// @Tags         grades
+// @Accept       json
+// @Param        body  body  model.CreateGradeRequest  true  "Grade data"
+// @Success      201   {object}  model.GradeResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /grades [post]
+func (h *Handler) CreateGrade(c echo.Context) error {
+	var req model.CreateGradeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	grade, err := h.service.CreateGrade(currentActor(c), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrNotAssigned) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, service.ErrInvalidGrade) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, grade)
+}
+
+// UpdateGrade godoc
+// @Summary      Correct a grade
+// @Tags         grades
+// @Accept       json
+// @Param        id    path  int                       true  "Grade ID"
+// @Param        body  body  model.UpdateGradeRequest  true  "New grade"
+// @Success      200   {object}  model.GradeResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /grades/{id} [patch]
+func (h *Handler) UpdateGrade(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid grade id"})
+	}
+
+	var req model.UpdateGradeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	grade, err := h.service.UpdateGrade(currentActor(c), id, &req)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "grade not found"})
+		}
+		if errors.Is(err, service.ErrNotAssigned) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, service.ErrInvalidGrade) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, grade)
+}
+
+// DeleteGrade godoc
+// @Summary      Delete a grade
+// @Tags         grades
+// @Param        id   path  int  true  "Grade ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /grades/{id} [delete]
+func (h *Handler) DeleteGrade(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid grade id"})
+	}
+
+	if err := h.service.DeleteGrade(id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "grade not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
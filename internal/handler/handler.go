@@ -2,7 +2,9 @@ package handler
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
@@ -10,6 +12,7 @@ import (
 	"university/internal/middleware"
 	"university/internal/model"
 	"university/internal/service"
+	"university/internal/storage"
 )
 
 type Handler struct {
@@ -22,43 +25,134 @@ func NewHandler(service *service.Service) *Handler {
 
 // Register registers HTTP routes on the provided Echo instance.
 func (h *Handler) Register(e *echo.Echo) {
+	auth := middleware.AuthMiddleware(h.service)
+	direct := middleware.DenyImpersonation()
+	admin := middleware.RequireRole(h.service, model.RoleAdmin)
+	staff := middleware.RequireRole(h.service, model.RoleAdmin, model.RoleTeacher)
+
+	// Read and attendance routes also accept API keys carrying the matching scope
+	readStudents := middleware.RequireRoleOrScope(h.service, model.ScopeStudentsRead, model.RoleAdmin, model.RoleTeacher)
+	readCatalog := middleware.RequireRoleOrScope(h.service, model.ScopeCatalogRead, model.RoleAdmin, model.RoleTeacher, model.RoleStudent)
+	readSchedule := middleware.RequireRoleOrScope(h.service, model.ScopeScheduleRead, model.RoleAdmin, model.RoleTeacher, model.RoleStudent)
+	readAttendance := middleware.RequireRoleOrScope(h.service, model.ScopeAttendanceRead, model.RoleAdmin, model.RoleTeacher)
+	writeAttendance := middleware.RequireRoleOrScope(h.service, model.ScopeAttendanceWriteAll, model.RoleAdmin, model.RoleTeacher)
+
+	// Public keys for verifying access tokens in other services
+	e.GET("/.well-known/jwks.json", h.GetJWKS)
+
 	// Public auth routes
 	e.POST("/api/auth/register", h.Register_User)
 	e.POST("/api/auth/login", h.Login)
+	e.POST("/api/auth/refresh", h.RefreshToken)
+	e.POST("/api/auth/password/forgot", h.ForgotPassword)
+	e.POST("/api/auth/password/reset", h.ResetPassword)
+	e.GET("/api/auth/verify", h.VerifyEmail)
+	e.POST("/api/auth/verify/resend", h.ResendVerification)
+	e.GET("/api/auth/oidc/login", h.OIDCLogin)
+	e.GET("/api/auth/oidc/callback", h.OIDCCallback)
+	e.POST("/api/auth/mfa/verify", h.VerifyMFA)
+	e.POST("/api/auth/mfa/enroll", h.EnrollMFAForLogin)
 
 	// Protected routes
-	e.GET("/api/users/me", h.GetCurrentUser, middleware.AuthMiddleware(h.service))
-
-	// Public student/schedule routes
-	e.GET("/student/:id", h.GetStudentByID)
-	e.GET("/students", h.GetAllStudents)
-	e.POST("/students", h.CreateStudent)
-	e.PATCH("/students/:id", h.UpdateStudent)
-	e.DELETE("/students/:id", h.DeleteStudent)
-	e.GET("/students/gpa", h.GetStudentsGPA)
-	e.GET("/subjects/stats", h.GetSubjectStats)
-	e.POST("/faculties", h.CreateFaculty)
-	e.GET("/faculties", h.GetAllFaculties)
-	e.GET("/faculties/:id", h.GetFacultyByID)
-	e.POST("/groups", h.CreateGroup)
-	e.GET("/groups", h.GetAllGroups)
-	e.GET("/groups/:id", h.GetGroupByID)
-	e.POST("/subjects", h.CreateSubject)
-	e.GET("/subjects", h.GetAllSubjects)
-	e.GET("/subjects/:id", h.GetSubjectByID)
-	e.GET("/all_class_schedule", h.GetAllSchedules)
-	e.GET("/schedule/group/:id", h.GetGroupSchedule)
-	e.GET("/schedule/:id", h.GetScheduleByID)
-	e.POST("/schedule", h.CreateSchedule)
-	e.PATCH("/schedule/:id", h.UpdateSchedule)
-	e.DELETE("/schedule/:id", h.DeleteSchedule)
-	e.GET("/attendance", h.GetAllAttendanceRecords)
-	e.GET("/attendance/student/:id", h.GetAttendanceRecordsByStudentID)
-	e.GET("/attendance/subject/:id", h.GetAttendanceRecordsBySubjectID)
-	e.GET("/attendance/:id", h.GetAttendanceByID)
-	e.POST("/attendance", h.CreateAttendanceRecord)
-	e.PATCH("/attendance/:id", h.UpdateAttendanceRecord)
-	e.DELETE("/attendance/:id", h.DeleteAttendanceRecord)
+	e.POST("/api/auth/logout", h.Logout, auth)
+	e.GET("/api/users/me", h.GetCurrentUser, auth)
+
+	// Self-service routes resolving the caller's student or staff record
+	e.GET("/api/me/profile", h.GetMyProfile, auth)
+	e.GET("/api/me/schedule", h.GetMySchedule, auth)
+	e.GET("/api/me/attendance", h.GetMyAttendance, auth)
+	e.GET("/api/me/grades", h.GetMyGrades, auth)
+	e.POST("/api/me/password", h.ChangePassword, auth, direct)
+	e.GET("/api/me/sessions", h.ListMySessions, auth)
+	e.DELETE("/api/me/sessions/:id", h.RevokeMySession, auth, direct)
+	e.POST("/api/me/mfa/enroll", h.EnrollMFA, auth, direct)
+	e.POST("/api/me/mfa/confirm", h.ConfirmMFA, auth, direct)
+	e.POST("/api/me/mfa/recovery-codes", h.RegenerateRecoveryCodes, auth, direct)
+	e.POST("/api/me/mfa/disable", h.DisableMFA, auth, direct)
+
+	// Admin user management routes
+	e.GET("/api/admin/users", h.ListUsers, auth, direct, admin)
+	e.PATCH("/api/admin/users/:id/status", h.UpdateUserStatus, auth, direct, admin)
+	e.POST("/api/admin/users/:id/roles", h.GrantUserRole, auth, direct, admin)
+	e.DELETE("/api/admin/users/:id/roles/:role", h.RevokeUserRole, auth, direct, admin)
+	e.POST("/api/admin/users/:id/password-reset", h.ForcePasswordReset, auth, direct, admin)
+	e.POST("/api/admin/users/:id/unlock", h.UnlockUser, auth, direct, admin)
+	e.DELETE("/api/admin/users/:id/mfa", h.ResetUserMFA, auth, direct, admin)
+	e.POST("/api/admin/users/:id/impersonate", h.StartImpersonation, auth, direct, admin)
+	e.GET("/api/admin/impersonations", h.ListImpersonations, auth, direct, admin)
+	e.GET("/api/admin/impersonations/:id/requests", h.ListImpersonatedRequests, auth, direct, admin)
+	e.POST("/api/admin/api-keys", h.CreateAPIKey, auth, direct, admin)
+	e.GET("/api/admin/api-keys", h.ListAPIKeys, auth, direct, admin)
+	e.DELETE("/api/admin/api-keys/:id", h.RevokeAPIKey, auth, direct, admin)
+	e.POST("/api/admin/teaching-assignments", h.CreateTeachingAssignment, auth, direct, admin)
+	e.GET("/api/admin/teaching-assignments", h.ListTeachingAssignments, auth, direct, admin)
+	e.DELETE("/api/admin/teaching-assignments/:id", h.DeleteTeachingAssignment, auth, direct, admin)
+	e.POST("/api/admin/invitations", h.CreateInvitation, auth, direct, admin)
+	e.GET("/api/admin/invitations", h.ListInvitations, auth, direct, admin)
+	e.DELETE("/api/admin/invitations/:id", h.DeleteInvitation, auth, direct, admin)
+	e.DELETE("/api/admin/students/:id", h.PurgeStudent, auth, direct, admin)
+
+	// Student routes: records are visible to staff, changes are admin-only
+	e.GET("/student/:id", h.GetStudentByID, auth, readStudents)
+	e.GET("/students", h.GetAllStudents, auth, readStudents)
+	e.GET("/students/export", h.ExportStudents, auth, readStudents)
+	e.POST("/students", h.CreateStudent, auth, admin)
+	e.POST("/students/import", h.ImportStudents, auth, admin)
+	e.PATCH("/students/:id", h.UpdateStudent, auth, admin)
+	e.DELETE("/students/:id", h.DeleteStudent, auth, admin)
+	e.POST("/students/:id/restore", h.RestoreStudent, auth, admin)
+	e.GET("/students/:id/history", h.GetStudentGroupHistory, auth, readStudents)
+	e.GET("/students/:id/group", h.GetStudentGroupOnDate, auth, readStudents)
+	e.POST("/students/:id/status", h.ChangeStudentStatus, auth, admin)
+	e.GET("/students/:id/status-history", h.GetStudentStatusChanges, auth, readStudents)
+	e.GET("/students/gpa", h.GetStudentsGPA, auth, readStudents)
+	e.GET("/subjects/stats", h.GetSubjectStats, auth, staff)
+
+	// Documents: admins manage any record's files, other users only their own.
+	// Downloads go through signed links instead of a token.
+	e.POST("/students/:id/documents", h.UploadStudentDocument, auth, direct)
+	e.GET("/students/:id/documents", h.ListStudentDocuments, auth, direct)
+	e.POST("/staff/:id/documents", h.UploadStaffDocument, auth, direct)
+	e.GET("/staff/:id/documents", h.ListStaffDocuments, auth, direct)
+	e.GET("/documents/:id/link", h.CreateDocumentLink, auth, direct)
+	e.GET("/documents/:id/download", h.DownloadDocument)
+	e.DELETE("/documents/:id", h.DeleteDocument, auth, direct)
+
+	// Search across people and the catalog, for staff and integrations reading students
+	e.GET("/search", h.Search, auth, readStudents)
+
+	// Catalog routes: readable by any member, managed by admins
+	e.POST("/faculties", h.CreateFaculty, auth, admin)
+	e.GET("/faculties", h.GetAllFaculties, auth, readCatalog)
+	e.GET("/faculties/:id", h.GetFacultyByID, auth, readCatalog)
+	e.POST("/groups", h.CreateGroup, auth, admin)
+	e.GET("/groups", h.GetAllGroups, auth, readCatalog)
+	e.GET("/groups/:id", h.GetGroupByID, auth, readCatalog)
+	e.POST("/subjects", h.CreateSubject, auth, admin)
+	e.GET("/subjects", h.GetAllSubjects, auth, readCatalog)
+	e.GET("/subjects/:id", h.GetSubjectByID, auth, readCatalog)
+
+	// Schedule routes
+	e.GET("/all_class_schedule", h.GetAllSchedules, auth, readSchedule)
+	e.GET("/schedule/group/:id", h.GetGroupSchedule, auth, readSchedule)
+	e.GET("/schedule/:id", h.GetScheduleByID, auth, readSchedule)
+	e.POST("/schedule", h.CreateSchedule, auth, admin)
+	e.PATCH("/schedule/:id", h.UpdateSchedule, auth, admin)
+	e.DELETE("/schedule/:id", h.DeleteSchedule, auth, admin)
+
+	// Attendance routes: teachers mark attendance for what they teach, only admins delete it
+	e.GET("/attendance", h.GetAllAttendanceRecords, auth, readAttendance)
+	e.GET("/attendance/student/:id", h.GetAttendanceRecordsByStudentID, auth, readAttendance)
+	e.GET("/attendance/subject/:id", h.GetAttendanceRecordsBySubjectID, auth, readAttendance)
+	e.GET("/attendance/:id", h.GetAttendanceByID, auth, readAttendance)
+	e.POST("/attendance", h.CreateAttendanceRecord, auth, writeAttendance)
+	e.PATCH("/attendance/:id", h.UpdateAttendanceRecord, auth, writeAttendance)
+	e.DELETE("/attendance/:id", h.DeleteAttendanceRecord, auth, admin)
+
+	// Grade routes: teachers post grades for what they teach, only admins delete them
+	e.POST("/grades", h.CreateGrade, auth, staff)
+	e.PATCH("/grades/:id", h.UpdateGrade, auth, staff)
+	e.DELETE("/grades/:id", h.DeleteGrade, auth, admin)
 }
 
 // GetStudentByID godoc
@@ -67,6 +161,8 @@ func (h *Handler) Register(e *echo.Echo) {
 // @Param        id   path      string  true  "Student ID"
 // @Success      200  {object}  model.StudentResponse
 // @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /student/{id} [get]
 func (h *Handler) GetStudentByID(c echo.Context) error {
 	id := c.Param("id")
@@ -88,12 +184,27 @@ func (h *Handler) GetStudentByID(c echo.Context) error {
 // GetAllStudents godoc
 // @Summary      Get all students
 // @Tags         students
-// @Success      200  {array}   model.StudentListResponse
+// @Param        group_id    query  int     false  "Only students of this group"
+// @Param        faculty_id  query  int     false  "Only students of this faculty"
+// @Param        archived    query  bool    false  "List archived students instead of current ones"
+// @Param        status      query  string  false  "Enrollment status, or all (default enrolled)"
+// @Param        limit       query  int     false  "Page size (default 50, max 500)"
+// @Param        offset      query  int     false  "Number of rows to skip"
+// @Param        sort        query  string  false  "id, first_name, last_name or group; prefix with - to sort descending"
+// @Success      200  {object}  model.Page[model.StudentListResponse]
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /students [get]
 func (h *Handler) GetAllStudents(c echo.Context) error {
-	students, err := h.service.GetAllStudents()
+	filter, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	students, err := h.service.GetAllStudents(filter, params)
+	if err != nil {
+		return listError(c, err)
 	}
 	return c.JSON(http.StatusOK, students)
 }
@@ -104,6 +215,7 @@ func (h *Handler) GetAllStudents(c echo.Context) error {
 // @Accept       json
 // @Param        body  body  model.CreateStudentRequest  true  "Student data"
 // @Success      201   {object}  model.StudentResponse
+// @Security     BearerAuth
 // @Router       /students [post]
 func (h *Handler) CreateStudent(c echo.Context) error {
 	var req model.CreateStudentRequest
@@ -120,10 +232,14 @@ func (h *Handler) CreateStudent(c echo.Context) error {
 
 // UpdateStudent godoc
 // @Summary      Update a student
+// @Description  A group change is recorded in the student's group history, effective from group_effective_from (default today).
 // @Tags         students
 // @Param        id    path      string  true  "Student ID"
 // @Param        body  body      model.UpdateStudentRequest  true  "Update data"
 // @Success      200   {object}  model.StudentResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /students/{id} [patch]
 func (h *Handler) UpdateStudent(c echo.Context) error {
 	id := c.Param("id")
@@ -141,16 +257,22 @@ func (h *Handler) UpdateStudent(c echo.Context) error {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "student not found"})
 		}
+		if errors.Is(err, service.ErrInvalidDate) || errors.Is(err, storage.ErrInvalidEffectiveDate) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, student)
 }
 
 // DeleteStudent godoc
-// @Summary      Delete a student
+// @Summary      Archive a student
+// @Description  Hides the student from listings, search and GPA while keeping their attendance and grades. Use the restore endpoint to undo, or the admin purge to delete for good.
 // @Tags         students
 // @Param        id   path  string  true  "Student ID"
 // @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
 // @Router       /students/{id} [delete]
 func (h *Handler) DeleteStudent(c echo.Context) error {
 	id := c.Param("id")
@@ -158,15 +280,32 @@ func (h *Handler) DeleteStudent(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id is required"})
 	}
 
-	err := h.service.DeleteStudent(id)
+	err := h.service.ArchiveStudent(id)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "student not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.NoContent(http.StatusNoContent)
 }
 
+// GetStudentsGPA godoc
+// @Summary      Get the GPA of every student with grades
+// @Tags         students
+// @Param        status  query  string  false  "Enrollment status, or all (default enrolled)"
+// @Success      200  {array}   model.StudentGPAResponse
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /students/gpa [get]
 func (h *Handler) GetStudentsGPA(c echo.Context) error {
-	gpaList, err := h.service.GetStudentsGPA()
+	status, err := statusFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	gpaList, err := h.service.GetStudentsGPA(status)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -187,6 +326,7 @@ func (h *Handler) GetSubjectStats(c echo.Context) error {
 // @Accept       json
 // @Param        body  body  model.CreateFacultyRequest  true  "Faculty data"
 // @Success      201   {object}  model.FacultyResponse
+// @Security     BearerAuth
 // @Router       /faculties [post]
 func (h *Handler) CreateFaculty(c echo.Context) error {
 	var req model.CreateFacultyRequest
@@ -203,12 +343,23 @@ func (h *Handler) CreateFaculty(c echo.Context) error {
 // GetAllFaculties godoc
 // @Summary      Get all faculties
 // @Tags         faculties
-// @Success      200  {array}  model.FacultyResponse
+// @Param        limit       query  int     false  "Page size (default 50, max 500)"
+// @Param        offset      query  int     false  "Number of rows to skip"
+// @Param        sort        query  string  false  "id or name; prefix with - to sort descending"
+// @Success      200  {object}  model.Page[model.FacultyResponse]
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /faculties [get]
 func (h *Handler) GetAllFaculties(c echo.Context) error {
-	faculties, err := h.service.GetAllFaculties()
+	_, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	faculties, err := h.service.GetAllFaculties(params)
+	if err != nil {
+		return listError(c, err)
 	}
 	return c.JSON(http.StatusOK, faculties)
 }
@@ -219,6 +370,8 @@ func (h *Handler) GetAllFaculties(c echo.Context) error {
 // @Param        id   path      string  true  "Faculty ID"
 // @Success      200  {object}  model.FacultyResponse
 // @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /faculties/{id} [get]
 func (h *Handler) GetFacultyByID(c echo.Context) error {
 	id := c.Param("id")
@@ -241,6 +394,7 @@ func (h *Handler) GetFacultyByID(c echo.Context) error {
 // @Accept       json
 // @Param        body  body  model.CreateGroupRequest  true  "Group data"
 // @Success      201   {object}  model.GroupResponse
+// @Security     BearerAuth
 // @Router       /groups [post]
 func (h *Handler) CreateGroup(c echo.Context) error {
 	var req model.CreateGroupRequest
@@ -257,12 +411,24 @@ func (h *Handler) CreateGroup(c echo.Context) error {
 // GetAllGroups godoc
 // @Summary      Get all groups
 // @Tags         groups
-// @Success      200  {array}  model.GroupResponse
+// @Param        faculty_id  query  int     false  "Only groups of this faculty"
+// @Param        limit       query  int     false  "Page size (default 50, max 500)"
+// @Param        offset      query  int     false  "Number of rows to skip"
+// @Param        sort        query  string  false  "id, name or faculty; prefix with - to sort descending"
+// @Success      200  {object}  model.Page[model.GroupResponse]
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /groups [get]
 func (h *Handler) GetAllGroups(c echo.Context) error {
-	groups, err := h.service.GetAllGroups()
+	filter, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	groups, err := h.service.GetAllGroups(filter, params)
+	if err != nil {
+		return listError(c, err)
 	}
 	return c.JSON(http.StatusOK, groups)
 }
@@ -273,6 +439,8 @@ func (h *Handler) GetAllGroups(c echo.Context) error {
 // @Param        id   path      string  true  "Group ID"
 // @Success      200  {object}  model.GroupResponse
 // @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /groups/{id} [get]
 func (h *Handler) GetGroupByID(c echo.Context) error {
 	id := c.Param("id")
@@ -295,6 +463,7 @@ func (h *Handler) GetGroupByID(c echo.Context) error {
 // @Accept       json
 // @Param        body  body  model.CreateSubjectRequest  true  "Subject data"
 // @Success      201   {object}  model.SubjectResponse
+// @Security     BearerAuth
 // @Router       /subjects [post]
 func (h *Handler) CreateSubject(c echo.Context) error {
 	var req model.CreateSubjectRequest
@@ -311,12 +480,23 @@ func (h *Handler) CreateSubject(c echo.Context) error {
 // GetAllSubjects godoc
 // @Summary      Get all subjects
 // @Tags         subjects
-// @Success      200  {array}  model.SubjectResponse
+// @Param        limit       query  int     false  "Page size (default 50, max 500)"
+// @Param        offset      query  int     false  "Number of rows to skip"
+// @Param        sort        query  string  false  "id or name; prefix with - to sort descending"
+// @Success      200  {object}  model.Page[model.SubjectResponse]
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /subjects [get]
 func (h *Handler) GetAllSubjects(c echo.Context) error {
-	subjects, err := h.service.GetAllSubjects()
+	_, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	subjects, err := h.service.GetAllSubjects(params)
+	if err != nil {
+		return listError(c, err)
 	}
 	return c.JSON(http.StatusOK, subjects)
 }
@@ -327,6 +507,8 @@ func (h *Handler) GetAllSubjects(c echo.Context) error {
 // @Param        id   path      string  true  "Subject ID"
 // @Success      200  {object}  model.SubjectResponse
 // @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /subjects/{id} [get]
 func (h *Handler) GetSubjectByID(c echo.Context) error {
 	id := c.Param("id")
@@ -346,25 +528,46 @@ func (h *Handler) GetSubjectByID(c echo.Context) error {
 // GetAllSchedules godoc
 // @Summary      Get all schedules
 // @Tags         schedules
-// @Success      200  {array}  model.ScheduleResponse
+// @Param        group_id    query  int     false  "Only classes of this group"
+// @Param        faculty_id  query  int     false  "Only classes of this faculty"
+// @Param        subject_id  query  int     false  "Only classes of this subject"
+// @Param        limit       query  int     false  "Page size (default 50, max 500)"
+// @Param        offset      query  int     false  "Number of rows to skip"
+// @Param        sort        query  string  false  "id, class_time, group or subject; prefix with - to sort descending"
+// @Success      200  {object}  model.Page[model.ScheduleResponse]
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /all_class_schedule [get]
 func (h *Handler) GetAllSchedules(c echo.Context) error {
-	schedules, err := h.service.GetAllSchedules()
+	filter, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	schedules, err := h.service.GetAllSchedules(filter, params)
+	if err != nil {
+		return listError(c, err)
 	}
 	return c.JSON(http.StatusOK, schedules)
 }
 
+// GetGroupSchedule takes the same query parameters as GetAllSchedules
 func (h *Handler) GetGroupSchedule(c echo.Context) error {
-	groupID := c.Param("id")
-	if groupID == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "group id is required"})
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid group id"})
 	}
 
-	schedules, err := h.service.GetGroupSchedule(groupID)
+	filter, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	filter.GroupID = groupID
+
+	schedules, err := h.service.GetAllSchedules(filter, params)
+	if err != nil {
+		return listError(c, err)
 	}
 	return c.JSON(http.StatusOK, schedules)
 }
@@ -435,12 +638,29 @@ func (h *Handler) DeleteSchedule(c echo.Context) error {
 // GetAllAttendanceRecords godoc
 // @Summary      Get all attendance records
 // @Tags         attendance
-// @Success      200  {array}  model.AttendanceRecord
+// @Param        student_id  query  int     false  "Only records of this student"
+// @Param        subject_id  query  int     false  "Only records of this subject"
+// @Param        group_id    query  int     false  "Only records of students who were in this group on the visit day"
+// @Param        status      query  string  false  "Students' enrollment status, or all (default enrolled unless student_id is given)"
+// @Param        from        query  string  false  "First visit day, YYYY-MM-DD"
+// @Param        to          query  string  false  "Last visit day, YYYY-MM-DD"
+// @Param        limit       query  int     false  "Page size (default 50, max 500)"
+// @Param        offset      query  int     false  "Number of rows to skip"
+// @Param        sort        query  string  false  "id, visit_day, student_id or subject_id; prefix with - to sort descending"
+// @Success      200  {object}  model.Page[model.AttendanceRecord]
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /attendance [get]
 func (h *Handler) GetAllAttendanceRecords(c echo.Context) error {
-	records, err := h.service.GetAllAttendanceRecords()
+	filter, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	records, err := h.service.GetAllAttendanceRecords(filter, params)
+	if err != nil {
+		return listError(c, err)
 	}
 	return c.JSON(http.StatusOK, records)
 }
@@ -467,6 +687,8 @@ func (h *Handler) GetAttendanceByID(c echo.Context) error {
 // @Accept       json
 // @Param        body  body  model.CreateAttendanceRequest  true  "Attendance data"
 // @Success      201   {object}  model.AttendanceRecord
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /attendance [post]
 func (h *Handler) CreateAttendanceRecord(c echo.Context) error {
 	var req model.CreateAttendanceRequest
@@ -474,8 +696,11 @@ func (h *Handler) CreateAttendanceRecord(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
-	created, err := h.service.CreateAttendanceRecord(&req)
+	created, err := h.service.CreateAttendanceRecord(currentActor(c), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrNotAssigned) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusCreated, created)
@@ -492,11 +717,14 @@ func (h *Handler) UpdateAttendanceRecord(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
-	record, err := h.service.UpdateAttendanceRecord(id, &req)
+	record, err := h.service.UpdateAttendanceRecord(currentActor(c), id, &req)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "attendance record not found"})
 		}
+		if errors.Is(err, service.ErrNotAssigned) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, record)
@@ -517,13 +745,15 @@ func (h *Handler) DeleteAttendanceRecord(c echo.Context) error {
 
 // Register_User godoc
 // @Summary      Register a new user
+// @Description  With an invitation code the account is linked to its student or staff record and granted the matching role.
 // @Tags         auth
 // @Accept       json
-// @Param        body  body  model.AuthRequest  true  "Email and password"
+// @Param        body  body  model.RegisterRequest  true  "Email, password and optional invitation code"
 // @Success      201   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]string
 // @Router       /api/auth/register [post]
 func (h *Handler) Register_User(c echo.Context) error {
-	var req model.AuthRequest
+	var req model.RegisterRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
@@ -541,10 +771,13 @@ func (h *Handler) Register_User(c echo.Context) error {
 
 // Login godoc
 // @Summary      Login
+// @Description  Returns tokens, or an mfa_token when the account needs a second factor (see /api/auth/mfa/verify).
 // @Tags         auth
 // @Accept       json
 // @Param        body  body  model.AuthRequest  true  "Email and password"
 // @Success      200   {object}  model.LoginResponse
+// @Failure      401   {object}  map[string]string
+// @Failure      429   {object}  map[string]string  "Too many failed attempts; see Retry-After header"
 // @Router       /api/auth/login [post]
 func (h *Handler) Login(c echo.Context) error {
 	var req model.AuthRequest
@@ -552,14 +785,217 @@ func (h *Handler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
-	response, err := h.service.Login(&req)
+	response, err := h.service.Login(&req, clientInfo(c))
 	if err != nil {
+		var locked *service.LoginLockedError
+		if errors.As(err, &locked) {
+			retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+			c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
+// GetJWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys for verifying access tokens, identified by kid.
+// @Tags         auth
+// @Success      200  {object}  model.JWKSResponse
+// @Router       /.well-known/jwks.json [get]
+func (h *Handler) GetJWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.service.JWKS())
+}
+
+// RefreshToken godoc
+// @Summary      Refresh access token
+// @Description  Exchanges a refresh token for a new access token. The refresh token is rotated on every use.
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  model.RefreshRequest  true  "Refresh token"
+// @Success      200   {object}  model.LoginResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /api/auth/refresh [post]
+func (h *Handler) RefreshToken(c echo.Context) error {
+	var req model.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	response, err := h.service.Refresh(&req, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenRequired):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidRefreshToken),
+			errors.Is(err, service.ErrRefreshTokenExpired),
+			errors.Is(err, service.ErrRefreshTokenReused),
+			errors.Is(err, service.ErrAccountInactive):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Emails a single-use reset link if an active account exists for the address.
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  model.ForgotPasswordRequest  true  "Account email"
+// @Success      202   {object}  map[string]string
+// @Failure      400   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /api/auth/password/forgot [post]
+func (h *Handler) ForgotPassword(c echo.Context) error {
+	var req model.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	if err := h.service.ForgotPassword(&req); err != nil {
+		if errors.Is(err, service.ErrEmailRequired) || errors.Is(err, service.ErrInvalidEmailFormat) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "if an account exists for this email, a reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  model.ResetPasswordRequest  true  "Reset token and new password"
+// @Success      200   {object}  map[string]string
+// @Failure      400   {object}  map[string]string
+// @Router       /api/auth/password/reset [post]
+func (h *Handler) ResetPassword(c echo.Context) error {
+	var req model.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	if err := h.service.ResetPassword(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "password has been reset"})
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Tags         auth
+// @Param        token  query  string  true  "Verification token from the email"
+// @Success      200    {object}  map[string]string
+// @Failure      400    {object}  map[string]string
+// @Router       /api/auth/verify [get]
+func (h *Handler) VerifyEmail(c echo.Context) error {
+	if err := h.service.VerifyEmail(c.QueryParam("token")); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "email verified"})
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  model.ResendVerificationRequest  true  "Account email"
+// @Success      202   {object}  map[string]string
+// @Failure      400   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /api/auth/verify/resend [post]
+func (h *Handler) ResendVerification(c echo.Context) error {
+	var req model.ResendVerificationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	if err := h.service.ResendVerification(&req); err != nil {
+		if errors.Is(err, service.ErrEmailRequired) || errors.Is(err, service.ErrInvalidEmailFormat) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "if the account exists and is unverified, a verification link has been sent",
+	})
+}
+
+// Logout godoc
+// @Summary      Logout
+// @Description  Revokes the current access token and, if supplied, the refresh token family.
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  model.LogoutRequest  false  "Refresh token to revoke"
+// @Success      204
+// @Security     BearerAuth
+// @Router       /api/auth/logout [post]
+func (h *Handler) Logout(c echo.Context) error {
+	claims, ok := c.Get("token_claims").(*service.AccessClaims)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	var req model.LogoutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	if err := h.service.Logout(claims, &req); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// currentUserID returns the authenticated user's ID stored by AuthMiddleware
+func currentUserID(c echo.Context) (int, bool) {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// currentActor describes who is making the request for ownership checks.
+// Roles are only known on routes guarded by RequireRole or RequireRoleOrScope.
+func currentActor(c echo.Context) model.Actor {
+	var actor model.Actor
+	actor.UserID, _ = currentUserID(c)
+	actor.Roles, _ = c.Get("roles").([]string)
+	if apiKey, ok := c.Get("api_key").(*model.APIKey); ok {
+		actor.APIKeyID = apiKey.ID
+		actor.Scopes = apiKey.Scopes
+	}
+	return actor
+}
+
+// clientInfo describes the client of the current request for session records
+func clientInfo(c echo.Context) model.ClientInfo {
+	return model.ClientInfo{
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
+
 // GetCurrentUser returns current user info (protected endpoint)
 func (h *Handler) GetCurrentUser(c echo.Context) error {
 	userID, ok := c.Get("user_id").(string)
@@ -575,32 +1011,51 @@ func (h *Handler) GetCurrentUser(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	// Let clients show that an admin is acting as this user
+	if claims, ok := c.Get("token_claims").(*service.AccessClaims); ok && claims.ActorID != 0 {
+		user.ImpersonatedBy = &claims.ActorID
+	}
+
 	return c.JSON(http.StatusOK, user)
 }
 
+// GetAttendanceRecordsByStudentID takes the same query parameters as GetAllAttendanceRecords
 func (h *Handler) GetAttendanceRecordsByStudentID(c echo.Context) error {
-	studentID := c.Param("id")
-	if studentID == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id is required"})
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid student id"})
 	}
 
-	records, err := h.service.GetAttendanceRecordsByStudentID(studentID)
+	filter, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	filter.StudentID = studentID
+
+	records, err := h.service.GetAllAttendanceRecords(filter, params)
+	if err != nil {
+		return listError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, records)
 }
 
+// GetAttendanceRecordsBySubjectID takes the same query parameters as GetAllAttendanceRecords
 func (h *Handler) GetAttendanceRecordsBySubjectID(c echo.Context) error {
-	subjectID := c.Param("id")
-	if subjectID == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id is required"})
+	subjectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid subject id"})
 	}
 
-	records, err := h.service.GetAttendanceRecordsBySubjectID(subjectID)
+	filter, params, err := listParams(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	filter.SubjectID = subjectID
+
+	records, err := h.service.GetAllAttendanceRecords(filter, params)
+	if err != nil {
+		return listError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, records)
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/service"
+)
+
+// GetStudentGroupHistory godoc
+// @Summary      Get a student's group history
+// @Description  Periods are oldest first. valid_to is exclusive and missing for the current group; valid_from is missing for a period that began before history was kept.
+// @Tags         students
+// @Param        id   path      string  true  "Student ID"
+// @Success      200  {array}   model.StudentGroupPeriod
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /students/{id}/history [get]
+func (h *Handler) GetStudentGroupHistory(c echo.Context) error {
+	periods, err := h.service.GetStudentGroupHistory(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "student not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, periods)
+}
+
+// GetStudentGroupOnDate godoc
+// @Summary      Get the group a student was in on a date
+// @Tags         students
+// @Param        id    path      string  true  "Student ID"
+// @Param        date  query     string  true  "Date, YYYY-MM-DD"
+// @Success      200   {object}  model.StudentGroupPeriod
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /students/{id}/group [get]
+func (h *Handler) GetStudentGroupOnDate(c echo.Context) error {
+	period, err := h.service.GetStudentGroupOnDate(c.Param("id"), c.QueryParam("date"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDate) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "no group recorded for the student on that date"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, period)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/service"
+)
+
+// StartImpersonation godoc
+// @Summary      Impersonate a user
+// @Description  Issues a short-lived, non-refreshable token acting as the user. Every request made with it is audited; password, two-factor, session and admin actions are blocked. Admins cannot be impersonated.
+// @Tags         admin
+// @Accept       json
+// @Param        id    path  int                       true  "User ID"
+// @Param        body  body  model.ImpersonateRequest  true  "Reason for the audit trail"
+// @Success      201   {object}  model.ImpersonationResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/impersonate [post]
+func (h *Handler) StartImpersonation(c echo.Context) error {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	var req model.ImpersonateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	response, err := h.service.StartImpersonation(actorID, userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		case errors.Is(err, service.ErrImpersonationNotAllowed):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// ListImpersonations godoc
+// @Summary      List impersonations
+// @Tags         admin
+// @Param        user_id  query  int  false  "Only impersonations of this user"
+// @Success      200      {array}   model.Impersonation
+// @Failure      400      {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/impersonations [get]
+func (h *Handler) ListImpersonations(c echo.Context) error {
+	userID := 0
+	if value := c.QueryParam("user_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user_id"})
+		}
+		userID = id
+	}
+
+	impersonations, err := h.service.ListImpersonations(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, impersonations)
+}
+
+// ListImpersonatedRequests godoc
+// @Summary      Audit trail of an impersonation
+// @Description  Every request made with the impersonation token, with its response status.
+// @Tags         admin
+// @Param        id   path  int  true  "Impersonation ID"
+// @Success      200  {array}   model.ImpersonatedRequest
+// @Failure      400  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/impersonations/{id}/requests [get]
+func (h *Handler) ListImpersonatedRequests(c echo.Context) error {
+	impersonationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid impersonation id"})
+	}
+
+	requests, err := h.service.ListImpersonatedRequests(impersonationID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, requests)
+}
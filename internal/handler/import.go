@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"university/internal/service"
+)
+
+// maxImportBytes bounds the size of an uploaded import file
+const maxImportBytes = 10 << 20
+
+// ImportStudents godoc
+// @Summary      Import students from CSV
+// @Description  Columns (header row required, any order): first_name, last_name, gender, birth_date (YYYY-MM-DD), group_name, email.
+// @Description  Every row is validated first; the import is applied in one transaction only when all rows are valid.
+// @Description  The file is sent as the "file" field of a multipart form or as a text/csv body.
+// @Tags         students
+// @Accept       multipart/form-data
+// @Accept       text/csv
+// @Produce      json
+// @Param        file             formData  file  false  "CSV file"
+// @Param        dry_run          query     bool  false  "Only validate and report"
+// @Param        create_accounts  query     bool  false  "Create a STUDENT account for every row and email a link to set its password; email becomes required"
+// @Success      200  {object}  model.StudentImportReport  "Dry run report"
+// @Success      201  {object}  model.StudentImportReport  "Students created"
+// @Failure      400  {object}  map[string]string
+// @Failure      422  {object}  model.StudentImportReport  "Some rows are invalid; nothing was written"
+// @Security     BearerAuth
+// @Router       /students/import [post]
+func (h *Handler) ImportStudents(c echo.Context) error {
+	var dryRun, createAccounts bool
+	for name, value := range map[string]*bool{"dry_run": &dryRun, "create_accounts": &createAccounts} {
+		if raw := c.QueryParam(name); raw != "" {
+			parsed, err := strconv.ParseBool(raw)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid " + name})
+			}
+			*value = parsed
+		}
+	}
+
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxImportBytes)
+
+	var file io.Reader = req.Body
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+		}
+		upload, err := header.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "failed to read file"})
+		}
+		defer upload.Close()
+		file = upload
+	}
+
+	report, err := h.service.ImportStudents(file, dryRun, createAccounts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImport) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	switch {
+	case report.Invalid > 0:
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	case report.Applied:
+		return c.JSON(http.StatusCreated, report)
+	default:
+		return c.JSON(http.StatusOK, report)
+	}
+}
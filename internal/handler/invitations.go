@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/storage"
+)
+
+// CreateInvitation godoc
+// @Summary      Invite a student or staff member
+// @Description  Issues a one-time code for registering an account linked to the record. Earlier unused codes for the record stop working. The code is only returned once.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      model.CreateInvitationRequest  true  "Record to invite"
+// @Success      201   {object}  model.CreateInvitationResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/invitations [post]
+func (h *Handler) CreateInvitation(c echo.Context) error {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	var req model.CreateInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	resp, err := h.service.CreateInvitation(actorID, &req)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "student or staff record not found"})
+		}
+		if errors.Is(err, storage.ErrRecordLinked) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": storage.ErrRecordLinked.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListInvitations godoc
+// @Summary      List invitations
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   model.Invitation
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/invitations [get]
+func (h *Handler) ListInvitations(c echo.Context) error {
+	invitations, err := h.service.ListInvitations()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, invitations)
+}
+
+// DeleteInvitation godoc
+// @Summary      Withdraw an unused invitation
+// @Tags         admin
+// @Param        id   path  int  true  "Invitation ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/invitations/{id} [delete]
+func (h *Handler) DeleteInvitation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid invitation id"})
+	}
+
+	if err := h.service.DeleteInvitation(id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "invitation not found or already used"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/storage"
+)
+
+// listParams reads the paging, sorting and filter query parameters shared by
+// list endpoints: limit, offset, sort, group_id, faculty_id, student_id,
+// subject_id, from, to, archived and status
+func listParams(c echo.Context) (*model.ListFilter, *model.ListParams, error) {
+	params := &model.ListParams{Sort: c.QueryParam("sort")}
+	filter := &model.ListFilter{From: c.QueryParam("from"), To: c.QueryParam("to")}
+
+	status, err := statusFilter(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter.Status = status
+
+	numbers := []struct {
+		name  string
+		value *int
+	}{
+		{"limit", &params.Limit},
+		{"offset", &params.Offset},
+		{"group_id", &filter.GroupID},
+		{"faculty_id", &filter.FacultyID},
+		{"student_id", &filter.StudentID},
+		{"subject_id", &filter.SubjectID},
+	}
+	for _, number := range numbers {
+		raw := c.QueryParam(number.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return nil, nil, fmt.Errorf("invalid %s", number.name)
+		}
+		*number.value = value
+	}
+
+	if raw := c.QueryParam("archived"); raw != "" {
+		archived, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, nil, errors.New("invalid archived")
+		}
+		filter.Archived = archived
+	}
+
+	for _, date := range []string{filter.From, filter.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, nil, errors.New("from and to must be dates in YYYY-MM-DD format")
+		}
+	}
+
+	return filter, params, nil
+}
+
+// statusFilter reads the enrollment status query parameter: a status, "all"
+// or empty for enrolled students only
+func statusFilter(c echo.Context) (string, error) {
+	status := c.QueryParam("status")
+	if status != "" && status != "all" && !slices.Contains(model.StudentStatuses, status) {
+		return "", errors.New("invalid status")
+	}
+	return status, nil
+}
+
+// listError responds to a failed list query
+func listError(c echo.Context, err error) error {
+	if errors.Is(err, storage.ErrInvalidSort) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
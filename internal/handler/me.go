@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/service"
+)
+
+// GetMyProfile godoc
+// @Summary      Get my profile
+// @Description  Returns the student or staff record linked to the logged-in account.
+// @Tags         me
+// @Success      200  {object}  model.ProfileResponse
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/profile [get]
+func (h *Handler) GetMyProfile(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	profile, err := h.service.GetMyProfile(userID)
+	if err != nil {
+		return meError(c, err)
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// GetMySchedule godoc
+// @Summary      Get my schedule
+// @Description  Students get their group's schedule, staff get their faculty's schedule.
+// @Tags         me
+// @Success      200  {array}   model.ScheduleResponse
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/schedule [get]
+func (h *Handler) GetMySchedule(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	schedules, err := h.service.GetMySchedule(userID)
+	if err != nil {
+		return meError(c, err)
+	}
+	return c.JSON(http.StatusOK, schedules)
+}
+
+// GetMyAttendance godoc
+// @Summary      Get my attendance
+// @Tags         me
+// @Success      200  {array}   model.AttendanceRecord
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/attendance [get]
+func (h *Handler) GetMyAttendance(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	records, err := h.service.GetMyAttendance(userID)
+	if err != nil {
+		return meError(c, err)
+	}
+	return c.JSON(http.StatusOK, records)
+}
+
+// GetMyGrades godoc
+// @Summary      Get my grades
+// @Tags         me
+// @Success      200  {array}   model.GradeResponse
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/grades [get]
+func (h *Handler) GetMyGrades(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	grades, err := h.service.GetMyGrades(userID)
+	if err != nil {
+		return meError(c, err)
+	}
+	return c.JSON(http.StatusOK, grades)
+}
+
+// ChangePassword godoc
+// @Summary      Change my password
+// @Description  Checks the current password, signs out all sessions and returns fresh tokens.
+// @Tags         me
+// @Accept       json
+// @Param        body  body  model.ChangePasswordRequest  true  "Current and new password"
+// @Success      200   {object}  model.LoginResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      429   {object}  map[string]string  "Too many failed attempts; see Retry-After header"
+// @Security     BearerAuth
+// @Router       /api/me/password [post]
+func (h *Handler) ChangePassword(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	var req model.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	response, err := h.service.ChangePassword(userID, &req, clientInfo(c))
+	if err != nil {
+		var locked *service.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+			c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrWrongPassword):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// meError maps errors from the self-service endpoints to responses
+func meError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrNoProfile) || errors.Is(err, service.ErrNotAStudent) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/service"
+)
+
+// VerifyMFA godoc
+// @Summary      Complete login with a second factor
+// @Description  Exchanges the mfa_token from login and a TOTP or recovery code for tokens.
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  model.MFAVerifyRequest  true  "mfa_token and code"
+// @Success      200   {object}  model.LoginResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Router       /api/auth/mfa/verify [post]
+func (h *Handler) VerifyMFA(c echo.Context) error {
+	var req model.MFAVerifyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	response, err := h.service.VerifyMFA(&req, clientInfo(c))
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// EnrollMFAForLogin godoc
+// @Summary      Enroll a second factor during login
+// @Description  For logins answered with mfa_enrollment_required. Confirm by calling /api/auth/mfa/verify with a code.
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  model.MFAEnrollRequest  true  "mfa_token from login"
+// @Success      200   {object}  model.MFAEnrollResponse
+// @Failure      401   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Router       /api/auth/mfa/enroll [post]
+func (h *Handler) EnrollMFAForLogin(c echo.Context) error {
+	var req model.MFAEnrollRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	response, err := h.service.EnrollMFAForLogin(&req)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// EnrollMFA godoc
+// @Summary      Start two-factor enrollment
+// @Description  Returns a new TOTP secret and otpauth URI. Takes effect once confirmed.
+// @Tags         me
+// @Success      200  {object}  model.MFAEnrollResponse
+// @Failure      409  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/mfa/enroll [post]
+func (h *Handler) EnrollMFA(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	response, err := h.service.EnrollMFA(userID)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// ConfirmMFA godoc
+// @Summary      Confirm two-factor enrollment
+// @Description  Enables two-factor authentication and returns recovery codes, shown only once.
+// @Tags         me
+// @Accept       json
+// @Param        body  body  model.MFACodeRequest  true  "Code from the authenticator app"
+// @Success      200   {object}  model.RecoveryCodesResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/mfa/confirm [post]
+func (h *Handler) ConfirmMFA(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	var req model.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	response, err := h.service.ConfirmMFA(userID, req.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate recovery codes
+// @Description  Replaces all recovery codes; requires a current TOTP or recovery code.
+// @Tags         me
+// @Accept       json
+// @Param        body  body  model.MFACodeRequest  true  "Current code"
+// @Success      200   {object}  model.RecoveryCodesResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/mfa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	var req model.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	response, err := h.service.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// DisableMFA godoc
+// @Summary      Disable two-factor authentication
+// @Description  Requires a current TOTP or recovery code. Not allowed for roles that require two-factor authentication.
+// @Tags         me
+// @Accept       json
+// @Param        body  body  model.MFACodeRequest  true  "Current code"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/mfa/disable [post]
+func (h *Handler) DisableMFA(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	var req model.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	if err := h.service.DisableMFA(userID, req.Code); err != nil {
+		return mfaError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ResetUserMFA godoc
+// @Summary      Reset a user's two-factor authentication
+// @Description  Removes the TOTP secret and recovery codes, e.g. after a lost device.
+// @Tags         admin
+// @Param        id   path  int  true  "User ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/mfa [delete]
+func (h *Handler) ResetUserMFA(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+	}
+
+	if err := h.service.ResetMFA(userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "two-factor authentication is not set up"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// mfaError maps two-factor errors to HTTP responses
+func mfaError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFAChallenge), errors.Is(err, service.ErrInvalidMFACode):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrMFARequired):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+}
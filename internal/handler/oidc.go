@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"university/internal/service"
+)
+
+// OIDCLogin godoc
+// @Summary      Start single sign-on
+// @Description  Redirects the browser to the university identity provider (authorization code flow with PKCE).
+// @Tags         auth
+// @Success      302
+// @Failure      502  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /api/auth/oidc/login [get]
+func (h *Handler) OIDCLogin(c echo.Context) error {
+	loginURL, err := h.service.OIDCLoginURL()
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCDisabled) {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+
+	return c.Redirect(http.StatusFound, loginURL)
+}
+
+// OIDCCallback godoc
+// @Summary      Complete single sign-on
+// @Description  Redirect target of the identity provider. Exchanges the code and returns our own tokens.
+// @Tags         auth
+// @Param        code   query  string  true  "Authorization code"
+// @Param        state  query  string  true  "Login state"
+// @Success      200    {object}  model.LoginResponse
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      503    {object}  map[string]string
+// @Router       /api/auth/oidc/callback [get]
+func (h *Handler) OIDCCallback(c echo.Context) error {
+	// The identity provider reports denied or failed logins as query parameters
+	if idpError := c.QueryParam("error"); idpError != "" {
+		message := "single sign-on failed: " + idpError
+		if description := c.QueryParam("error_description"); description != "" {
+			message += ": " + description
+		}
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": message})
+	}
+
+	response, err := h.service.OIDCCallback(c.QueryParam("code"), c.QueryParam("state"), clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCDisabled):
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidOIDCState):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Search godoc
+// @Summary      Search students, staff, groups and subjects
+// @Description  Matches names, emails, group and subject names, tolerating typos and Cyrillic/Latin spellings. Results are ranked by similarity and carry highlighted copies of their title and subtitle.
+// @Tags         search
+// @Produce      json
+// @Param        q      query  string  true   "Search text, at least 2 characters"
+// @Param        type   query  string  false  "Comma-separated result types: student, staff, group, subject"
+// @Param        limit  query  int     false  "Maximum number of results (default 20, max 100)"
+// @Success      200    {array}   model.SearchResult
+// @Failure      400    {object}  map[string]string
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /search [get]
+func (h *Handler) Search(c echo.Context) error {
+	var types []string
+	if param := c.QueryParam("type"); param != "" {
+		types = strings.Split(param, ",")
+	}
+
+	limit := 0
+	if param := c.QueryParam("limit"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = value
+	}
+
+	results, err := h.service.Search(c.QueryParam("q"), types, limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/service"
+)
+
+// ListMySessions godoc
+// @Summary      List my sessions
+// @Description  Devices the caller is logged in on; the session of this request is marked current.
+// @Tags         me
+// @Success      200  {array}   model.Session
+// @Failure      401  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/sessions [get]
+func (h *Handler) ListMySessions(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	currentSessionID := 0
+	if claims, ok := c.Get("token_claims").(*service.AccessClaims); ok {
+		currentSessionID = claims.SessionID
+	}
+
+	sessions, err := h.service.ListSessions(userID, currentSessionID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeMySession godoc
+// @Summary      Revoke one of my sessions
+// @Description  Signs the device out; its tokens stop working immediately.
+// @Tags         me
+// @Param        id   path  int  true  "Session ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/me/sessions/{id} [delete]
+func (h *Handler) RevokeMySession(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user context"})
+	}
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid session id"})
+	}
+
+	if err := h.service.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "session not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
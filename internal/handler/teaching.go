@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"university/internal/model"
+	"university/internal/storage"
+)
+
+// CreateTeachingAssignment godoc
+// @Summary      Assign a group and subject to a teacher
+// @Description  The group must belong to the staff member's faculty. Assigning the same triple twice is a no-op.
+// @Tags         admin
+// @Accept       json
+// @Param        body  body  model.CreateTeachingAssignmentRequest  true  "Assignment"
+// @Success      201   {object}  model.TeachingAssignment
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/teaching-assignments [post]
+func (h *Handler) CreateTeachingAssignment(c echo.Context) error {
+	var req model.CreateTeachingAssignmentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	assignment, err := h.service.CreateTeachingAssignment(&req)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "staff member or group not found"})
+		}
+		if errors.Is(err, storage.ErrFacultyMismatch) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": storage.ErrFacultyMismatch.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, assignment)
+}
+
+// ListTeachingAssignments godoc
+// @Summary      List teaching assignments
+// @Tags         admin
+// @Param        staff_id  query  int  false  "Only assignments of this staff member"
+// @Success      200       {array}   model.TeachingAssignment
+// @Failure      400       {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/teaching-assignments [get]
+func (h *Handler) ListTeachingAssignments(c echo.Context) error {
+	staffID := 0
+	if param := c.QueryParam("staff_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid staff_id"})
+		}
+		staffID = id
+	}
+
+	assignments, err := h.service.ListTeachingAssignments(staffID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, assignments)
+}
+
+// DeleteTeachingAssignment godoc
+// @Summary      Remove a teaching assignment
+// @Tags         admin
+// @Param        id   path  int  true  "Assignment ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/admin/teaching-assignments/{id} [delete]
+func (h *Handler) DeleteTeachingAssignment(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid assignment id"})
+	}
+
+	if err := h.service.DeleteTeachingAssignment(id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "teaching assignment not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+)
+
+// Message is an outgoing plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(msg *Message) error
+}
+
+// NewSenderFromEnv builds the sender selected by MAIL_DRIVER ("smtp" or "outbox").
+// The outbox driver is the default and stores messages in the database, which is
+// handy for local development and testing.
+func NewSenderFromEnv(store OutboxStore) (Sender, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@university.kz"
+	}
+
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST environment variable is not set")
+		}
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return NewSMTPSender(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from), nil
+	case "", "outbox":
+		return NewOutboxSender(store, from), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER: %s", driver)
+	}
+}
@@ -0,0 +1,25 @@
+package mail
+
+import "fmt"
+
+// OutboxStore persists outgoing messages
+type OutboxStore interface {
+	CreateOutboxMessage(sender, recipient, subject, body string) error
+}
+
+// OutboxSender writes messages to the mail_outbox table instead of delivering them
+type OutboxSender struct {
+	store OutboxStore
+	from  string
+}
+
+func NewOutboxSender(store OutboxStore, from string) *OutboxSender {
+	return &OutboxSender{store: store, from: from}
+}
+
+func (s *OutboxSender) Send(msg *Message) error {
+	if err := s.store.CreateOutboxMessage(s.from, msg.To, msg.Subject, msg.Body); err != nil {
+		return fmt.Errorf("failed to store email in outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPSender sends messages through an SMTP relay
+type SMTPSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPSender(host, port, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *SMTPSender) Send(msg *Message) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+
+	addr := net.JoinHostPort(s.host, s.port)
+	if err := smtp.SendMail(addr, auth, s.from, []string{msg.To}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -1,18 +1,37 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"university/internal/model"
 	"university/internal/service"
 
 	"github.com/labstack/echo/v4"
 )
 
-// AuthMiddleware validates JWT token from Authorization header
-// Uses: Authorization: Bearer <token>
+// AuthMiddleware validates JWT token from Authorization header, or an API key
+// Uses: Authorization: Bearer <token> or X-API-Key: <key>
 func AuthMiddleware(svc *service.Service) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			// Service integrations authenticate with an API key instead of a user token
+			if key := c.Request().Header.Get("X-API-Key"); key != "" {
+				apiKey, err := svc.AuthenticateAPIKey(key)
+				if err != nil {
+					return c.JSON(http.StatusUnauthorized, map[string]string{
+						"error": "invalid or expired API key",
+					})
+				}
+
+				// Store API key in context; there is no user for this request
+				c.Set("api_key", apiKey)
+
+				return next(c)
+			}
+
 			// Get Authorization header
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
@@ -32,17 +51,160 @@ func AuthMiddleware(svc *service.Service) echo.MiddlewareFunc {
 			tokenString := parts[1]
 
 			// Validate token
-			userID, err := svc.ValidateToken(tokenString)
+			claims, err := svc.ValidateToken(tokenString)
 			if err != nil {
 				return c.JSON(http.StatusUnauthorized, map[string]string{
 					"error": "invalid or expired token",
 				})
 			}
 
-			// Store user ID in context for handler access
-			c.Set("user_id", userID)
+			// Reject tokens of users deactivated after the token was issued
+			active, err := svc.IsUserActive(claims.UserID)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "failed to load user",
+				})
+			}
+			if !active {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "user account is inactive",
+				})
+			}
+
+			// Reject tokens whose session was revoked, e.g. signed out from another device
+			if claims.SessionID != 0 {
+				active, err := svc.IsSessionActive(claims.SessionID)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]string{
+						"error": "failed to load session",
+					})
+				}
+				if !active {
+					return c.JSON(http.StatusUnauthorized, map[string]string{
+						"error": "session has been revoked",
+					})
+				}
+			}
+
+			// Impersonation tokens stop working once the impersonation ends or expires
+			if claims.ImpersonationID != 0 {
+				active, err := svc.IsImpersonationActive(claims.ImpersonationID)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]string{
+						"error": "failed to load impersonation",
+					})
+				}
+				if !active {
+					return c.JSON(http.StatusUnauthorized, map[string]string{
+						"error": "impersonation has ended",
+					})
+				}
+			}
+
+			// Store user ID and claims in context for handler access
+			c.Set("user_id", strconv.Itoa(claims.UserID))
+			c.Set("token_claims", claims)
+
+			if claims.ImpersonationID != 0 {
+				return auditImpersonation(c, svc, claims.ImpersonationID, next)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// auditImpersonation records a request made with an impersonation token before
+// running it, and its response status afterwards. Requests that cannot be
+// recorded are refused.
+func auditImpersonation(c echo.Context, svc *service.Service, impersonationID int, next echo.HandlerFunc) error {
+	req := c.Request()
+	requestID, err := svc.RecordImpersonatedRequest(impersonationID, req.Method, req.URL.RequestURI(), c.RealIP())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to audit request",
+		})
+	}
+
+	err = next(c)
+
+	status := c.Response().Status
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+	} else if err != nil && !c.Response().Committed {
+		status = http.StatusInternalServerError
+	}
+
+	if auditErr := svc.SetImpersonatedRequestStatus(requestID, status); auditErr != nil && err == nil {
+		return auditErr
+	}
+	return err
+}
 
+// DenyImpersonation rejects requests made with an impersonation token. It guards
+// sensitive actions such as password changes and role grants and must be
+// chained after AuthMiddleware.
+func DenyImpersonation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if claims, ok := c.Get("token_claims").(*service.AccessClaims); ok && claims.ActorID != 0 {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "not allowed while impersonating",
+				})
+			}
 			return next(c)
 		}
 	}
 }
+
+// RequireRole allows the request only if the authenticated user has at least
+// one of the given roles. API key callers are rejected. It must be chained
+// after AuthMiddleware.
+func RequireRole(svc *service.Service, roles ...string) echo.MiddlewareFunc {
+	return RequireRoleOrScope(svc, "", roles...)
+}
+
+// RequireRoleOrScope is like RequireRole but also admits API key callers whose
+// key carries the given scope. An empty scope admits no API keys.
+func RequireRoleOrScope(svc *service.Service, scope string, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if apiKey, ok := c.Get("api_key").(*model.APIKey); ok {
+				if scope != "" && slices.Contains(apiKey.Scopes, scope) {
+					return next(c)
+				}
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "insufficient permissions",
+				})
+			}
+
+			userID, ok := c.Get("user_id").(string)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "invalid user context",
+				})
+			}
+
+			userRoles, err := svc.GetUserRoles(userID)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "failed to load user roles",
+				})
+			}
+
+			// Store roles in context so handlers don't have to reload them
+			c.Set("roles", userRoles)
+
+			for _, have := range userRoles {
+				if slices.Contains(roles, have) {
+					return next(c)
+				}
+			}
+
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "insufficient permissions",
+			})
+		}
+	}
+}
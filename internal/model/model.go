@@ -2,6 +2,25 @@ package model
 
 import "time"
 
+// Role names as stored in the roles table
+const (
+	RoleAdmin   = "ADMIN"
+	RoleTeacher = "TEACHER"
+	RoleStudent = "STUDENT"
+)
+
+// Enrollment statuses of a student; only enrolled students are active
+const (
+	StudentEnrolled    = "enrolled"
+	StudentOnLeave     = "on_leave"
+	StudentGraduated   = "graduated"
+	StudentExpelled    = "expelled"
+	StudentTransferred = "transferred"
+)
+
+// StudentStatuses lists every enrollment status
+var StudentStatuses = []string{StudentEnrolled, StudentOnLeave, StudentGraduated, StudentExpelled, StudentTransferred}
+
 type StudentResponse struct {
 	ID        int    `json:"id"`
 	FirstName string `json:"first_name"`
@@ -9,14 +28,61 @@ type StudentResponse struct {
 	Gender    string `json:"gender"`
 	BirthDate string `json:"birth_date"`
 	GroupName string `json:"group_name"`
+	Status    string `json:"status"`
+	// ArchivedAt is set once the student is archived
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 type StudentListResponse struct {
-	ID        int    `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	GroupName string `json:"group_name"`
-	Email     string `json:"email"`
+	ID         int        `json:"id"`
+	FirstName  string     `json:"first_name"`
+	LastName   string     `json:"last_name"`
+	GroupName  string     `json:"group_name"`
+	Email      string     `json:"email"`
+	Status     string     `json:"status"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// ChangeStudentStatusRequest moves a student to another enrollment status
+type ChangeStudentStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// StudentStatusChange records one enrollment status transition, why it was
+// made and by whom
+type StudentStatusChange struct {
+	ID         int       `json:"id"`
+	StudentID  int       `json:"student_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedBy  *int      `json:"changed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// StudentPurgeResult reports what purging an archived student removed
+type StudentPurgeResult struct {
+	StudentID         int `json:"student_id"`
+	AttendanceDeleted int `json:"attendance_deleted"`
+	GradesDeleted     int `json:"grades_deleted"`
+	DocumentsDeleted  int `json:"documents_deleted"`
+	// UndeletedFiles lists storage keys whose files could not be removed and
+	// need to be cleaned up by hand
+	UndeletedFiles []string `json:"undeleted_files,omitempty"`
+}
+
+// StudentExportRow is one student in a CSV or XLSX export
+type StudentExportRow struct {
+	ID          int
+	FirstName   string
+	LastName    string
+	Gender      string
+	BirthDate   string
+	GroupName   string
+	FacultyName string
+	Email       string
+	Status      string
 }
 
 type CreateStudentRequest struct {
@@ -35,6 +101,20 @@ type UpdateStudentRequest struct {
 	BirthDate *string `json:"birth_date,omitempty"`
 	GroupID   *int    `json:"group_id,omitempty"`
 	GroupName *string `json:"group_name,omitempty"`
+	// GroupEffectiveFrom dates a group change, YYYY-MM-DD; defaults to today
+	GroupEffectiveFrom *string `json:"group_effective_from,omitempty"`
+}
+
+// StudentGroupPeriod is a span of time a student spent in a group. ValidFrom
+// is empty for a period that began before history was kept; ValidTo is
+// exclusive and empty for the current group.
+type StudentGroupPeriod struct {
+	ID        int    `json:"id"`
+	StudentID int    `json:"student_id"`
+	GroupID   *int   `json:"group_id"`
+	GroupName string `json:"group_name"`
+	ValidFrom string `json:"valid_from,omitempty"`
+	ValidTo   string `json:"valid_to,omitempty"`
 }
 
 type CreateScheduleRequest struct {
@@ -127,11 +207,12 @@ type CreateSubjectRequest struct {
 
 // User represents a user account
 type User struct {
-	ID           int       `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID              int        `json:"id"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
 
 // AuthRequest is the payload for both registration and login
@@ -140,17 +221,450 @@ type AuthRequest struct {
 	Password string `json:"password"`
 }
 
-// LoginResponse is returned after successful login
+// RegisterRequest is the payload for creating an account. An invitation code
+// links the account to its student or staff record and grants the matching role.
+type RegisterRequest struct {
+	Email          string `json:"email"`
+	Password       string `json:"password"`
+	InvitationCode string `json:"invitation_code,omitempty"`
+}
+
+// LoginResponse is returned after successful login or token refresh.
+// When a second factor is needed, tokens and user are omitted, mfa_token is set
+// with expires_in as its lifetime, and the login is completed at /api/auth/mfa/verify.
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  *User  `json:"user"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+	User         *User  `json:"user,omitempty"`
+
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"` // enroll with the mfa_token first
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`          // set once when enrollment completes during login
+}
+
+// Actor is the caller a write is authorized for: a user with roles, or an API key
+type Actor struct {
+	UserID   int
+	Roles    []string
+	APIKeyID int
+	Scopes   []string
+}
+
+// TeachingAssignment states that a staff member teaches a subject to a group
+type TeachingAssignment struct {
+	ID        int       `json:"id"`
+	StaffID   int       `json:"staff_id"`
+	GroupID   int       `json:"group_id"`
+	Group     string    `json:"group"`
+	SubjectID int       `json:"subject_id"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateTeachingAssignmentRequest assigns a subject and group to a staff member
+type CreateTeachingAssignmentRequest struct {
+	StaffID   int `json:"staff_id"`
+	GroupID   int `json:"group_id"`
+	SubjectID int `json:"subject_id"`
+}
+
+// CreateGradeRequest posts a grade for a student in a subject
+type CreateGradeRequest struct {
+	StudentID int     `json:"student_id"`
+	SubjectID int     `json:"subject_id"`
+	Grade     float64 `json:"grade"`
+}
+
+// UpdateGradeRequest corrects a grade
+type UpdateGradeRequest struct {
+	Grade float64 `json:"grade"`
+}
+
+// ClientInfo describes the client a request came from
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Session is a login on one device, tied to a refresh token family
+type Session struct {
+	ID         int       `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// ImpersonateRequest starts an impersonation; the reason is kept in the audit trail
+type ImpersonateRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonationResponse carries an access token acting as another user.
+// It cannot be refreshed; the admin's own token stays valid meanwhile.
+type ImpersonationResponse struct {
+	Token           string `json:"token"`
+	ExpiresIn       int64  `json:"expires_in"`
+	ImpersonationID int    `json:"impersonation_id"`
+	User            *User  `json:"user"`
+}
+
+// Impersonation is an audited period in which an admin acted as another user
+type Impersonation struct {
+	ID           int        `json:"id"`
+	ActorID      int        `json:"actor_id"`
+	ActorEmail   string     `json:"actor_email"`
+	UserID       int        `json:"user_id"`
+	UserEmail    string     `json:"user_email"`
+	Reason       string     `json:"reason"`
+	StartedAt    time.Time  `json:"started_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	EndedAt      *time.Time `json:"ended_at"`
+	RequestCount int        `json:"request_count"`
+}
+
+// ImpersonatedRequest is one request made with an impersonation token
+type ImpersonatedRequest struct {
+	ID        int       `json:"id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    *int      `json:"status"`
+	IPAddress string    `json:"ip_address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChangePasswordRequest is the payload for changing the caller's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// MFAVerifyRequest completes a login with a TOTP or recovery code
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// MFAEnrollRequest starts TOTP enrollment during a login that requires it
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// MFAEnrollResponse carries a new TOTP secret for the authenticator app
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest carries a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// RecoveryCodesResponse lists one-time recovery codes; they are only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshRequest is the payload for exchanging a refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ForgotPasswordRequest starts the password reset flow
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest sets a new password using a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ResendVerificationRequest asks for a new email verification link
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// LogoutRequest optionally carries the refresh token to revoke on logout
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 // UserResponse is the user info returned in /api/users/me
 type UserResponse struct {
+	ID              int        `json:"id"`
+	Email           string     `json:"email"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Roles           []string   `json:"roles"`
+	ImpersonatedBy  *int       `json:"impersonated_by,omitempty"` // real admin behind an impersonation token
+}
+
+// UserFilter narrows down the admin user list
+// ListParams are the paging and sorting query parameters shared by list endpoints
+type ListParams struct {
+	Limit  int    // page size; defaults to 50 and is capped at 500
+	Offset int    // number of rows to skip
+	Sort   string // comma-separated fields, each prefixed with "-" for descending order
+}
+
+// ListFilter narrows list endpoints. Each endpoint honours the fields that
+// apply to its rows and ignores the others.
+type ListFilter struct {
+	GroupID   int
+	FacultyID int
+	StudentID int
+	SubjectID int
+	From      string // inclusive date, YYYY-MM-DD
+	To        string // inclusive date, YYYY-MM-DD
+	Archived  bool   // students: list archived records instead of current ones
+	Status    string // students: enrollment status, "all" for any; enrolled when empty
+}
+
+// Page is the response envelope of list endpoints. Total counts all rows
+// matching the filters, so clients can page with offset until it is reached.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type UserFilter struct {
+	Query  string // email substring
+	Role   string
+	Active *bool
+}
+
+// UpdateUserStatusRequest activates or deactivates a user account
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active"`
+}
+
+// RoleRequest names a role to grant to a user
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+// StudentProfile is the student record linked to a user account
+type StudentProfile struct {
+	ID          int    `json:"id"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Gender      string `json:"gender"`
+	BirthDate   string `json:"birth_date"`
+	GroupID     int    `json:"group_id"`
+	GroupName   string `json:"group_name"`
+	FacultyID   int    `json:"faculty_id"`
+	FacultyName string `json:"faculty_name"`
+}
+
+// StaffProfile is the staff record linked to a user account
+type StaffProfile struct {
+	ID          int    `json:"id"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	FacultyID   int    `json:"faculty_id"`
+	FacultyName string `json:"faculty_name"`
+	Position    string `json:"position"`
+}
+
+// ProfileResponse is returned by /api/me/profile
+type ProfileResponse struct {
+	Type    string          `json:"type"` // "student" or "staff"
+	Email   string          `json:"email"`
+	Roles   []string        `json:"roles"`
+	Student *StudentProfile `json:"student,omitempty"`
+	Staff   *StaffProfile   `json:"staff,omitempty"`
+}
+
+// GradeResponse is a single grade with its subject name
+type GradeResponse struct {
 	ID        int       `json:"id"`
-	Email     string    `json:"email"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	Roles     []string  `json:"roles"`
+	StudentID int       `json:"student_id"`
+	SubjectID int       `json:"subject_id"`
+	Subject   string    `json:"subject"`
+	Grade     float64   `json:"grade"`
+	GradedAt  time.Time `json:"graded_at"`
+}
+
+// JWK is a public JSON Web Key used to verify access tokens
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKSResponse is a JSON Web Key Set
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+// API key scopes granted to service integrations
+const (
+	ScopeCatalogRead    = "catalog:read"
+	ScopeScheduleRead   = "schedule:read"
+	ScopeStudentsRead   = "students:read"
+	ScopeAttendanceRead = "attendance:read"
+	// ScopeAttendanceWriteAll records attendance for any student and subject.
+	// Keys have no teaching assignments, so this scope is not limited by them.
+	ScopeAttendanceWriteAll = "attendance:write:all"
+)
+
+// APIKey is a credential for service-to-service integrations. The key itself
+// is only returned once, on creation.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *int       `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyRequest is the payload for issuing an API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"` // 0 means the key never expires
+}
+
+// CreateAPIKeyResponse carries the plaintext key, shown only once
+type CreateAPIKeyResponse struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
+
+// Invitation lets a new account claim a student or staff record on registration.
+// The code itself is only returned once, on creation.
+type Invitation struct {
+	ID        int        `json:"id"`
+	StudentID *int       `json:"student_id"`
+	StaffID   *int       `json:"staff_id"`
+	Role      string     `json:"role"`
+	Email     *string    `json:"email"`
+	CreatedBy *int       `json:"created_by"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	UsedBy    *int       `json:"used_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateInvitationRequest is the payload for inviting a student or staff member.
+// Exactly one of StudentID and StaffID must be set.
+type CreateInvitationRequest struct {
+	StudentID     int    `json:"student_id,omitempty"`
+	StaffID       int    `json:"staff_id,omitempty"`
+	Email         string `json:"email,omitempty"`           // when set, only this address may use the code
+	ExpiresInDays int    `json:"expires_in_days,omitempty"` // defaults to INVITATION_TTL
+}
+
+// CreateInvitationResponse carries the plaintext code, shown only once
+type CreateInvitationResponse struct {
+	Code       string      `json:"code"`
+	Invitation *Invitation `json:"invitation"`
+}
+
+// Search result types
+const (
+	SearchStudent = "student"
+	SearchStaff   = "staff"
+	SearchGroup   = "group"
+	SearchSubject = "subject"
+)
+
+// SearchResult is one match of a search. The highlighted fields repeat title
+// and subtitle, HTML-escaped, with the words matching the query wrapped in <mark>.
+type SearchResult struct {
+	Type                string  `json:"type"`
+	ID                  int     `json:"id"`
+	Title               string  `json:"title"`
+	Subtitle            string  `json:"subtitle,omitempty"`
+	HighlightedTitle    string  `json:"highlighted_title"`
+	HighlightedSubtitle string  `json:"highlighted_subtitle,omitempty"`
+	Score               float64 `json:"score"`
+}
+
+// ImportStudent is a validated row of a student import
+type ImportStudent struct {
+	Line    int
+	Student CreateStudentRequest
+	Email   string // account email; empty when no account is created
+}
+
+// StudentImportRow reports the outcome of one CSV row. Line numbers count the
+// header as line 1.
+type StudentImportRow struct {
+	Line      int      `json:"line"`
+	StudentID int      `json:"student_id,omitempty"`
+	UserID    int      `json:"user_id,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// StudentImportReport is the result of a student import. Nothing is written
+// unless every row is valid and the import is not a dry run.
+type StudentImportReport struct {
+	DryRun          bool               `json:"dry_run"`
+	Applied         bool               `json:"applied"`
+	Total           int                `json:"total"`
+	Invalid         int                `json:"invalid"`
+	AccountsCreated int                `json:"accounts_created"`
+	Rows            []StudentImportRow `json:"rows"`
+}
+
+// Document kinds
+const (
+	DocumentPhoto              = "photo"
+	DocumentMedicalCertificate = "medical_certificate"
+	DocumentContract           = "contract"
+	DocumentOther              = "other"
+)
+
+// Document is the metadata of a file attached to a student or staff record.
+// The file itself lives in the blob store under StorageKey.
+type Document struct {
+	ID          int       `json:"id"`
+	StudentID   *int      `json:"student_id,omitempty"`
+	StaffID     *int      `json:"staff_id,omitempty"`
+	Kind        string    `json:"kind"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	StorageKey  string    `json:"-"`
+	UploadedBy  *int      `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UploadDocumentRequest describes an uploaded file; exactly one of StudentID
+// and StaffID is set
+type UploadDocumentRequest struct {
+	StudentID int
+	StaffID   int
+	Kind      string
+	FileName  string
+	Size      int64
+}
+
+// DocumentLink is a signed download link that works without authentication
+// until it expires
+type DocumentLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
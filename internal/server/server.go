@@ -23,6 +23,10 @@ func NewServer(handler *handler.Handler) *Server {
 func (s *Server) Start(addr string) error {
 	e := echo.New()
 
+	// Resolve client IPs from X-Forwarded-For, trusting only private proxy hops
+	// (Render's load balancer), so clients cannot spoof their address
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
+
 	// Add global middleware
 	e.Use(middleware.Recover())
 	e.Use(middleware.RequestLogger())
@@ -30,8 +34,8 @@ func (s *Server) Start(addr string) error {
 	// CORS: allow browser requests from any origin (e.g. frontend on different domain)
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-API-Key"},
 	}))
 
 	// Register all routes (public and protected)
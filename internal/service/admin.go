@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"university/internal/model"
+)
+
+// ErrInvalidAdminRequest is returned when a user administration request fails
+// validation
+var ErrInvalidAdminRequest = errors.New("invalid request")
+
+// ListUsers returns user accounts with their roles for administration
+func (s *Service) ListUsers(filter *model.UserFilter, params *model.ListParams) (*model.Page[model.UserResponse], error) {
+	if filter.Role != "" && !isKnownRole(filter.Role) {
+		return nil, fmt.Errorf("%w: unknown role %s", ErrInvalidAdminRequest, filter.Role)
+	}
+	return s.repo.ListUsers(filter, params)
+}
+
+// SetUserActive activates or deactivates a user account. Deactivated users are
+// signed out everywhere. Admins cannot deactivate themselves.
+func (s *Service) SetUserActive(actorID, userID int, active bool) (*model.UserResponse, error) {
+	if actorID == userID && !active {
+		return nil, fmt.Errorf("%w: you cannot deactivate your own account", ErrInvalidAdminRequest)
+	}
+
+	if err := s.repo.SetUserActive(userID, active); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUserByID(strconv.Itoa(userID))
+}
+
+// GrantRole assigns a role to a user account
+func (s *Service) GrantRole(userID int, role string) (*model.UserResponse, error) {
+	if !isKnownRole(role) {
+		return nil, fmt.Errorf("%w: unknown role %s", ErrInvalidAdminRequest, role)
+	}
+
+	// Make sure the user exists before touching roles
+	if _, err := s.repo.GetUserAccountByID(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.GrantUserRole(userID, role); err != nil {
+		return nil, fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	return s.repo.GetUserByID(strconv.Itoa(userID))
+}
+
+// RevokeRole removes a role from a user account. Admins cannot drop their own ADMIN role.
+func (s *Service) RevokeRole(actorID, userID int, role string) (*model.UserResponse, error) {
+	if !isKnownRole(role) {
+		return nil, fmt.Errorf("%w: unknown role %s", ErrInvalidAdminRequest, role)
+	}
+
+	if actorID == userID && role == model.RoleAdmin {
+		return nil, fmt.Errorf("%w: you cannot revoke your own admin role", ErrInvalidAdminRequest)
+	}
+
+	if _, err := s.repo.GetUserAccountByID(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RevokeUserRole(userID, role); err != nil {
+		return nil, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	return s.repo.GetUserByID(strconv.Itoa(userID))
+}
+
+// ForcePasswordReset invalidates the user's current password, signs them out
+// everywhere and emails them a password reset link
+func (s *Service) ForcePasswordReset(userID int) error {
+	user, err := s.repo.GetUserAccountByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.InvalidatePassword(user.ID); err != nil {
+		return fmt.Errorf("failed to invalidate password: %w", err)
+	}
+
+	return s.sendPasswordResetEmail(user)
+}
+
+// isKnownRole reports whether role is one of the roles defined in the roles table
+func isKnownRole(role string) bool {
+	switch role {
+	case model.RoleAdmin, model.RoleTeacher, model.RoleStudent:
+		return true
+	}
+	return false
+}
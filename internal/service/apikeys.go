@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidAPIKey is returned when an API key is unknown, revoked or expired
+var ErrInvalidAPIKey = errors.New("invalid or expired API key")
+
+// ErrInvalidAPIKeyRequest is returned when a new API key fails validation
+var ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+
+// CreateAPIKey issues a new API key with the given scopes
+func (s *Service) CreateAPIKey(actorID int, req *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range req.Scopes {
+		if !isKnownScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %s", ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+
+	if req.ExpiresInDays < 0 {
+		return nil, fmt.Errorf("%w: expires_in_days must not be negative", ErrInvalidAPIKeyRequest)
+	}
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+
+	prefix, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	key := "uk_" + prefix + "_" + secret
+
+	apiKey, err := s.repo.CreateAPIKey(name, prefix, hashToken(key), req.Scopes, actorID, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &model.CreateAPIKeyResponse{Key: key, APIKey: apiKey}, nil
+}
+
+// ListAPIKeys returns all API keys without their secrets
+func (s *Service) ListAPIKeys() ([]model.APIKey, error) {
+	return s.repo.ListAPIKeys()
+}
+
+// RevokeAPIKey revokes an API key immediately
+func (s *Service) RevokeAPIKey(id int) error {
+	return s.repo.RevokeAPIKey(id)
+}
+
+// AuthenticateAPIKey resolves an API key presented in a request
+func (s *Service) AuthenticateAPIKey(key string) (*model.APIKey, error) {
+	apiKey, err := s.repo.GetActiveAPIKeyByHash(hashToken(key))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to check API key: %w", err)
+	}
+	return apiKey, nil
+}
+
+// isKnownScope reports whether scope is one of the defined API key scopes
+func isKnownScope(scope string) bool {
+	switch scope {
+	case model.ScopeCatalogRead, model.ScopeScheduleRead, model.ScopeStudentsRead,
+		model.ScopeAttendanceRead, model.ScopeAttendanceWriteAll:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"university/internal/model"
+)
+
+// Errors returned by document operations
+var (
+	ErrInvalidDocument = errors.New("invalid document")
+	ErrDocumentAccess  = errors.New("no access to these documents")
+	ErrInvalidLink     = errors.New("invalid or expired download link")
+)
+
+// documentTypes lists the content types accepted for each document kind.
+// Types are sniffed from the file itself, not taken from the client.
+var documentTypes = map[string][]string{
+	model.DocumentPhoto:              {"image/jpeg", "image/png", "image/webp"},
+	model.DocumentMedicalCertificate: {"application/pdf", "image/jpeg", "image/png"},
+	model.DocumentContract:           {"application/pdf"},
+	model.DocumentOther:              {"application/pdf", "image/jpeg", "image/png"},
+}
+
+// loadDocumentLinkKey reads the key that signs download links from
+// DOCUMENT_LINK_SECRET. Without it the service refuses to start when APP_ENV
+// is "production"; otherwise a random key is used and links stop working on
+// restart.
+func loadDocumentLinkKey() ([]byte, error) {
+	if secret := os.Getenv("DOCUMENT_LINK_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	if os.Getenv("APP_ENV") == "production" {
+		return nil, errors.New("no download link key configured: set DOCUMENT_LINK_SECRET")
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate download link key: %w", err)
+	}
+	return key, nil
+}
+
+// authorizeDocuments checks that the actor may manage the documents of a
+// student or staff record: admins may manage any, other users only their own
+func (s *Service) authorizeDocuments(actor model.Actor, studentID, staffID int) error {
+	if actor.UserID == 0 {
+		return ErrDocumentAccess
+	}
+
+	admin, err := s.isAdmin(actor.UserID)
+	if err != nil {
+		return err
+	}
+	if admin {
+		return nil
+	}
+
+	if studentID != 0 {
+		student, err := s.repo.GetStudentByUserID(actor.UserID)
+		if err == nil && student.ID == studentID {
+			return nil
+		}
+	} else {
+		staff, err := s.repo.GetStaffByUserID(actor.UserID)
+		if err == nil && staff.ID == staffID {
+			return nil
+		}
+	}
+	return ErrDocumentAccess
+}
+
+// isAdmin reports whether the user holds the ADMIN role
+func (s *Service) isAdmin(userID int) (bool, error) {
+	roles, err := s.repo.GetUserRoles(strconv.Itoa(userID))
+	if err != nil {
+		return false, fmt.Errorf("failed to load roles: %w", err)
+	}
+	return slices.Contains(roles, model.RoleAdmin), nil
+}
+
+// UploadDocument validates a file and stores it with its metadata. The
+// content type is detected from the file and must suit the document kind.
+func (s *Service) UploadDocument(actor model.Actor, req *model.UploadDocumentRequest, file io.Reader) (*model.Document, error) {
+	if err := s.authorizeDocuments(actor, req.StudentID, req.StaffID); err != nil {
+		return nil, err
+	}
+
+	allowed, ok := documentTypes[req.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: kind must be one of photo, medical_certificate, contract, other", ErrInvalidDocument)
+	}
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidDocument)
+	}
+	if req.Size > s.documentMaxSize {
+		return nil, fmt.Errorf("%w: file is larger than %d bytes", ErrInvalidDocument, s.documentMaxSize)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	head = head[:n]
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !slices.Contains(allowed, contentType) {
+		return nil, fmt.Errorf("%w: %s files are not accepted as %s; use %s",
+			ErrInvalidDocument, contentType, req.Kind, strings.Join(allowed, ", "))
+	}
+
+	suffix, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head), file), hash)
+	key := "documents/" + suffix
+	if err := s.blobs.Put(key, body, req.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	doc := &model.Document{
+		Kind:        req.Kind,
+		FileName:    documentFileName(req.FileName),
+		ContentType: contentType,
+		Size:        req.Size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:  key,
+		UploadedBy:  &actor.UserID,
+	}
+	if req.StudentID != 0 {
+		doc.StudentID = &req.StudentID
+	} else {
+		doc.StaffID = &req.StaffID
+	}
+
+	created, err := s.repo.CreateDocument(doc)
+	if err != nil {
+		if delErr := s.blobs.Delete(key); delErr != nil {
+			s.logger.Errorw("failed to delete file of unsaved document", "storage_key", key, "error", delErr)
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+// documentFileName keeps the base name of an uploaded file for display and
+// downloads
+func documentFileName(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "document"
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[len(runes)-255:])
+	}
+	return name
+}
+
+// ListDocuments returns the documents of a student or staff record
+func (s *Service) ListDocuments(actor model.Actor, studentID, staffID int) ([]model.Document, error) {
+	if err := s.authorizeDocuments(actor, studentID, staffID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDocuments(studentID, staffID)
+}
+
+// DeleteDocument removes a document and its file. Users other than admins may
+// only delete documents they uploaded to their own record.
+func (s *Service) DeleteDocument(actor model.Actor, id int) error {
+	doc, err := s.repo.GetDocument(id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeDocuments(actor, deref(doc.StudentID), deref(doc.StaffID)); err != nil {
+		return err
+	}
+
+	// Owners may only delete what they uploaded themselves, not documents
+	// such as contracts that an admin filed on their record
+	if deref(doc.UploadedBy) != actor.UserID {
+		admin, err := s.isAdmin(actor.UserID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return ErrDocumentAccess
+		}
+	}
+
+	if _, err := s.repo.DeleteDocument(id); err != nil {
+		return err
+	}
+	// The metadata is gone, so a file that fails to delete is only unreachable;
+	// log its key so it can be removed by hand
+	if err := s.blobs.Delete(doc.StorageKey); err != nil {
+		s.logger.Errorw("failed to delete document file", "document_id", id, "storage_key", doc.StorageKey, "error", err)
+	}
+	return nil
+}
+
+// CreateDocumentLink returns a signed path that downloads a document without
+// authentication until it expires
+func (s *Service) CreateDocumentLink(actor model.Actor, id int) (*model.DocumentLink, error) {
+	doc, err := s.repo.GetDocument(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeDocuments(actor, deref(doc.StudentID), deref(doc.StaffID)); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.documentLinkTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	return &model.DocumentLink{
+		URL: fmt.Sprintf("/documents/%d/download?expires=%d&signature=%s",
+			doc.ID, expires, s.documentLinkSignature(doc.ID, expires)),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// OpenDocument checks a download link and opens the document's file; the
+// caller closes it
+func (s *Service) OpenDocument(id int, expires int64, signature string) (*model.Document, io.ReadCloser, error) {
+	expected := s.documentLinkSignature(id, expires)
+	if !hmac.Equal([]byte(signature), []byte(expected)) || time.Now().Unix() > expires {
+		return nil, nil, ErrInvalidLink
+	}
+
+	doc, err := s.repo.GetDocument(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.blobs.Get(doc.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return doc, file, nil
+}
+
+func (s *Service) documentLinkSignature(id int, expires int64) string {
+	mac := hmac.New(sha256.New, s.documentLinkKey)
+	fmt.Fprintf(mac, "document:%d:%d", id, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func deref(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// DocumentMaxSize returns the largest accepted upload in bytes
+func (s *Service) DocumentMaxSize() int64 {
+	return s.documentMaxSize
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"university/internal/model"
+)
+
+// ErrInvalidStatusChange is returned when a status change names an unknown
+// status or gives no reason
+var ErrInvalidStatusChange = errors.New("invalid status change")
+
+// studentTransitions lists the statuses a student may move to from each
+// status. Graduated and transferred students leave for good.
+var studentTransitions = map[string][]string{
+	model.StudentEnrolled: {model.StudentOnLeave, model.StudentGraduated, model.StudentExpelled, model.StudentTransferred},
+	model.StudentOnLeave:  {model.StudentEnrolled, model.StudentExpelled, model.StudentTransferred},
+	model.StudentExpelled: {model.StudentEnrolled},
+}
+
+// ChangeStudentStatus moves a student to another enrollment status along the
+// allowed transitions, recording the reason and the acting user
+func (s *Service) ChangeStudentStatus(actorID int, id string, req *model.ChangeStudentStatusRequest) (*model.StudentStatusChange, error) {
+	if !slices.Contains(model.StudentStatuses, req.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalidStatusChange, strings.Join(model.StudentStatuses, ", "))
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidStatusChange)
+	}
+
+	var allowedFrom []string
+	for from, to := range studentTransitions {
+		if slices.Contains(to, req.Status) {
+			allowedFrom = append(allowedFrom, from)
+		}
+	}
+
+	return s.repo.ChangeStudentStatus(id, req.Status, allowedFrom, req.Reason, actorID)
+}
+
+// GetStudentStatusChanges returns a student's status changes, oldest first
+func (s *Service) GetStudentStatusChanges(id string) ([]model.StudentStatusChange, error) {
+	return s.repo.GetStudentStatusChanges(id)
+}
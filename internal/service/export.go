@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"university/internal/export"
+	"university/internal/model"
+)
+
+// ErrInvalidExport is returned when an export asks for an unknown format or column
+var ErrInvalidExport = errors.New("invalid export")
+
+// studentExportColumns are the columns a student export can contain, in
+// their default order
+var studentExportColumns = []struct {
+	name  string
+	value func(*model.StudentExportRow) string
+}{
+	{"id", func(s *model.StudentExportRow) string { return strconv.Itoa(s.ID) }},
+	{"first_name", func(s *model.StudentExportRow) string { return s.FirstName }},
+	{"last_name", func(s *model.StudentExportRow) string { return s.LastName }},
+	{"gender", func(s *model.StudentExportRow) string { return s.Gender }},
+	{"birth_date", func(s *model.StudentExportRow) string { return s.BirthDate }},
+	{"group", func(s *model.StudentExportRow) string { return s.GroupName }},
+	{"faculty", func(s *model.StudentExportRow) string { return s.FacultyName }},
+	{"email", func(s *model.StudentExportRow) string { return s.Email }},
+	{"status", func(s *model.StudentExportRow) string { return s.Status }},
+}
+
+// ExportStudents streams the students matching filter to w as CSV or XLSX.
+// columns selects and orders the columns; empty means all of them. Nothing is
+// written to w when the request is invalid or the query fails to start.
+func (s *Service) ExportStudents(w io.Writer, format string, columns []string, filter *model.ListFilter, sort string) error {
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		return fmt.Errorf("%w: format must be csv or xlsx", ErrInvalidExport)
+	}
+
+	if len(columns) == 0 {
+		for _, column := range studentExportColumns {
+			columns = append(columns, column.name)
+		}
+	}
+	var values []func(*model.StudentExportRow) string
+	seen := map[string]bool{}
+	for _, name := range columns {
+		name = strings.TrimSpace(name)
+		index := -1
+		for i, column := range studentExportColumns {
+			if column.name == name {
+				index = i
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("%w: unknown column: %s", ErrInvalidExport, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: duplicate column: %s", ErrInvalidExport, name)
+		}
+		seen[name] = true
+		values = append(values, studentExportColumns[index].value)
+	}
+
+	out, err := export.New(w, format, "Students")
+	if err != nil {
+		return err
+	}
+
+	// The header goes out with the first row so a query that fails to start
+	// leaves w untouched
+	header := make([]string, len(columns))
+	for i, name := range columns {
+		header[i] = strings.TrimSpace(name)
+	}
+	wroteHeader := false
+	writeHeader := func() error {
+		if wroteHeader {
+			return nil
+		}
+		wroteHeader = true
+		return out.Write(header)
+	}
+
+	record := make([]string, len(values))
+	err = s.repo.StreamStudents(filter, sort, func(student *model.StudentExportRow) error {
+		if err := writeHeader(); err != nil {
+			return err
+		}
+		for i, value := range values {
+			record[i] = value(student)
+		}
+		return out.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeHeader(); err != nil {
+		return err
+	}
+	return out.Close()
+}
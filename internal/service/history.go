@@ -0,0 +1,24 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"university/internal/model"
+)
+
+// ErrInvalidDate is returned when a date parameter is malformed or out of range
+var ErrInvalidDate = errors.New("invalid date")
+
+// GetStudentGroupHistory returns the groups a student has been in, oldest first
+func (s *Service) GetStudentGroupHistory(id string) ([]model.StudentGroupPeriod, error) {
+	return s.repo.GetStudentGroupHistory(id)
+}
+
+// GetStudentGroupOnDate returns the group a student was in on date, YYYY-MM-DD
+func (s *Service) GetStudentGroupOnDate(id, date string) (*model.StudentGroupPeriod, error) {
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return nil, fmt.Errorf("%w: date must be in YYYY-MM-DD format", ErrInvalidDate)
+	}
+	return s.repo.GetStudentGroupOnDate(id, date)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"university/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrImpersonationNotAllowed is returned for impersonation targets that may not be impersonated
+var ErrImpersonationNotAllowed = errors.New("this user cannot be impersonated")
+
+// StartImpersonation issues an access token that acts as the target user on
+// behalf of the admin actor. The token carries both users, cannot be refreshed
+// and every request made with it is audited. Admins cannot be impersonated.
+func (s *Service) StartImpersonation(actorID, userID int, req *model.ImpersonateRequest) (*model.ImpersonationResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	if actorID == userID {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	user, err := s.repo.GetUserAccountByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, errors.New("user account is inactive")
+	}
+
+	roles, err := s.repo.GetUserRoles(strconv.Itoa(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	if slices.Contains(roles, model.RoleAdmin) {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	impersonationID, err := s.repo.StartImpersonation(actorID, userID, reason, s.impersonationTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record impersonation: %w", err)
+	}
+
+	tokenID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	token, err := s.keys.sign(AccessClaims{
+		UserID:          user.ID,
+		Email:           user.Email,
+		ActorID:         actorID,
+		ImpersonationID: impersonationID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    s.jwtIssuer,
+			Subject:   strconv.Itoa(user.ID),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.impersonationTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ImpersonationResponse{
+		Token:           token,
+		ExpiresIn:       int64(s.impersonationTTL.Seconds()),
+		ImpersonationID: impersonationID,
+		User:            user,
+	}, nil
+}
+
+// IsImpersonationActive reports whether an impersonation token may still be used
+func (s *Service) IsImpersonationActive(impersonationID int) (bool, error) {
+	return s.repo.IsImpersonationActive(impersonationID)
+}
+
+// RecordImpersonatedRequest adds a request to the impersonation audit trail and
+// returns the entry ID for SetImpersonatedRequestStatus
+func (s *Service) RecordImpersonatedRequest(impersonationID int, method, path, clientIP string) (int, error) {
+	return s.repo.RecordImpersonatedRequest(impersonationID, method, truncate(path, 2048), clientIP)
+}
+
+// SetImpersonatedRequestStatus stores the response status of an audited request
+func (s *Service) SetImpersonatedRequestStatus(requestID, status int) error {
+	return s.repo.SetImpersonatedRequestStatus(requestID, status)
+}
+
+// ListImpersonations returns impersonations, optionally of one user (userID 0 for all)
+func (s *Service) ListImpersonations(userID int) ([]model.Impersonation, error) {
+	return s.repo.ListImpersonations(userID)
+}
+
+// ListImpersonatedRequests returns the audit trail of an impersonation
+func (s *Service) ListImpersonatedRequests(impersonationID int) ([]model.ImpersonatedRequest, error) {
+	return s.repo.ListImpersonatedRequests(impersonationID)
+}
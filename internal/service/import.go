@@ -0,0 +1,259 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"university/internal/mail"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxImportRows bounds the size of one student import
+const maxImportRows = 10000
+
+// ErrInvalidImport is returned when an import file cannot be read as a student CSV
+var ErrInvalidImport = errors.New("invalid import")
+
+// importColumns are the CSV columns of a student import, in any order
+var importColumns = []string{"first_name", "last_name", "gender", "birth_date", "group_name", "email"}
+
+// ImportStudents validates a CSV of students and, unless dryRun is set or any
+// row is invalid, creates all of them in one transaction. With createAccounts
+// every row needs an email, and each student gets a STUDENT account and an
+// email with a link to choose a password.
+func (s *Service) ImportStudents(r io.Reader, dryRun, createAccounts bool) (*model.StudentImportReport, error) {
+	records, err := readImportCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	students, report, err := s.validateImport(records, createAccounts)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = dryRun
+
+	if dryRun || report.Invalid > 0 {
+		return report, nil
+	}
+
+	if err := s.repo.ImportStudents(students, report.Rows); err != nil {
+		return nil, fmt.Errorf("failed to import students: %w", err)
+	}
+	report.Applied = true
+
+	// Accounts are committed by now; a failed email only leaves the student
+	// needing a password reset later, so it is reported as a warning
+	for i, student := range students {
+		if student.Email == "" {
+			continue
+		}
+		report.AccountsCreated++
+		user := &model.User{ID: report.Rows[i].UserID, Email: student.Email}
+		if err := s.sendAccountSetupEmail(user); err != nil {
+			report.Rows[i].Warnings = append(report.Rows[i].Warnings, "failed to send account setup email: "+err.Error())
+		}
+	}
+
+	return report, nil
+}
+
+// readImportCSV reads the header and rows of an import, keyed by column name
+func readImportCSV(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV is empty")
+		}
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(importColumns, name) {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column: %s", name)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	for _, required := range []string{"first_name", "last_name", "group_name"} {
+		if !seen[required] {
+			return nil, fmt.Errorf("missing column: %s", required)
+		}
+	}
+
+	var records []map[string]string
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(records) == maxImportRows {
+			return nil, fmt.Errorf("an import may have at most %d rows", maxImportRows)
+		}
+
+		record := map[string]string{}
+		for i, value := range fields {
+			if i < len(columns) {
+				record[columns[i]] = strings.TrimSpace(value)
+			}
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("CSV has no rows")
+	}
+	return records, nil
+}
+
+// validateImport checks every row, resolving group names and looking for
+// emails that are taken or repeated
+func (s *Service) validateImport(records []map[string]string, createAccounts bool) ([]model.ImportStudent, *model.StudentImportReport, error) {
+	report := &model.StudentImportReport{
+		Total: len(records),
+		Rows:  make([]model.StudentImportRow, len(records)),
+	}
+	students := make([]model.ImportStudent, len(records))
+
+	var emails []string
+	for _, record := range records {
+		if record["email"] != "" {
+			emails = append(emails, record["email"])
+		}
+	}
+	taken, err := s.repo.ExistingEmails(emails)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check emails: %w", err)
+	}
+
+	groups := map[string]int{}
+	emailLines := map[string]int{}
+
+	for i, record := range records {
+		line := i + 2
+		var problems []string
+
+		firstName, lastName := record["first_name"], record["last_name"]
+		if firstName == "" || lastName == "" {
+			problems = append(problems, "first_name and last_name are required")
+		}
+		if len([]rune(firstName)) > 50 || len([]rune(lastName)) > 50 {
+			problems = append(problems, "names may be at most 50 characters")
+		}
+
+		gender := record["gender"]
+		if len([]rune(gender)) > 10 {
+			problems = append(problems, "gender may be at most 10 characters")
+		}
+
+		birthDate := record["birth_date"]
+		if birthDate != "" {
+			date, err := time.Parse(time.DateOnly, birthDate)
+			if err != nil {
+				problems = append(problems, "birth_date must be a date in YYYY-MM-DD format")
+			} else if date.After(time.Now()) {
+				problems = append(problems, "birth_date is in the future")
+			}
+		}
+
+		groupName := record["group_name"]
+		groupID, known := groups[groupName]
+		if groupName == "" {
+			problems = append(problems, "group_name is required")
+		} else if !known {
+			id, err := s.repo.GetGroupIDByName(groupName)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return nil, nil, fmt.Errorf("failed to resolve group %s: %w", groupName, err)
+			}
+			groups[groupName] = id
+			groupID = id
+		}
+		if groupName != "" && groupID == 0 {
+			problems = append(problems, fmt.Sprintf("group not found: %s", groupName))
+		}
+
+		email := record["email"]
+		key := strings.ToLower(email)
+		switch {
+		case email == "" && createAccounts:
+			problems = append(problems, "email is required to create an account")
+		case email == "":
+		case !isValidEmail(email):
+			problems = append(problems, "invalid email format")
+		case taken[key]:
+			problems = append(problems, "a user with this email already exists")
+		case emailLines[key] != 0:
+			problems = append(problems, fmt.Sprintf("email repeats line %d", emailLines[key]))
+		default:
+			emailLines[key] = line
+		}
+
+		report.Rows[i] = model.StudentImportRow{Line: line, Errors: problems}
+		if len(problems) > 0 {
+			report.Invalid++
+		}
+
+		students[i] = model.ImportStudent{
+			Line: line,
+			Student: model.CreateStudentRequest{
+				FirstName: firstName,
+				LastName:  lastName,
+				Gender:    gender,
+				BirthDate: birthDate,
+				GroupID:   groupID,
+				GroupName: groupName,
+			},
+		}
+		if createAccounts {
+			students[i].Email = email
+		}
+	}
+
+	return students, report, nil
+}
+
+// sendAccountSetupEmail emails an imported student a link to choose the
+// password of their new account. The link is a password reset that stays
+// valid as long as an invitation.
+func (s *Service) sendAccountSetupEmail(user *model.User) error {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.CreatePasswordResetToken(user.ID, tokenHash, s.invitationTTL); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appBaseURL, url.QueryEscape(token))
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: "Your university account",
+		Body: fmt.Sprintf(
+			"An account was created for you with this email address.\n\n"+
+				"Use the link below to choose your password. It expires in %s and can be used once.\n\n%s\n",
+			s.invitationTTL, link,
+		),
+	}
+	return s.mailer.Send(msg)
+}
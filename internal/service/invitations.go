@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidInvitation is returned when an invitation code is unknown, used,
+// expired or meant for another email address
+var ErrInvalidInvitation = errors.New("invalid or expired invitation code")
+
+// CreateInvitation issues an invitation code for a student or staff record
+// without an account. Students are granted STUDENT and staff TEACHER on
+// registration. The code is only returned once.
+func (s *Service) CreateInvitation(actorID int, req *model.CreateInvitationRequest) (*model.CreateInvitationResponse, error) {
+	if (req.StudentID == 0) == (req.StaffID == 0) {
+		return nil, errors.New("exactly one of student_id and staff_id is required")
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email != "" && !isValidEmail(req.Email) {
+		return nil, errors.New("invalid email format")
+	}
+
+	if req.ExpiresInDays < 0 {
+		return nil, errors.New("expires_in_days must not be negative")
+	}
+	ttl := s.invitationTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	role := model.RoleStudent
+	if req.StaffID != 0 {
+		role = model.RoleTeacher
+	}
+
+	raw, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	code := raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+
+	invitation, err := s.repo.CreateInvitation(req, role, hashToken(raw), actorID, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return &model.CreateInvitationResponse{Code: code, Invitation: invitation}, nil
+}
+
+// ListInvitations returns all invitations without their codes
+func (s *Service) ListInvitations() ([]model.Invitation, error) {
+	return s.repo.ListInvitations()
+}
+
+// DeleteInvitation withdraws an unused invitation
+func (s *Service) DeleteInvitation(id int) error {
+	return s.repo.DeleteInvitation(id)
+}
+
+// registerInvited creates an account that claims the record of an invitation
+func (s *Service) registerInvited(email, passwordHash, code string) (*model.User, error) {
+	user, err := s.repo.CreateInvitedUser(email, passwordHash, hashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidInvitation
+	}
+	return user, err
+}
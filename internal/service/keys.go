@@ -0,0 +1,236 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"university/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is a JWT key identified by its kid. Retired keys only carry the
+// public half and are kept so tokens signed before a rotation stay valid.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// keyRing holds every key accepted for verification and the one used for signing
+type keyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// loadKeyRing loads PEM keys from JWT_KEYS_DIR, one key per file named <kid>.pem.
+// RSA keys sign with RS256 and Ed25519 keys with EdDSA. Private keys can sign,
+// public keys are verify-only. JWT_ACTIVE_KID selects the signing key and defaults
+// to the last private key in lexical order, so date-based kids rotate naturally.
+//
+// To rotate: add the new key file, point JWT_ACTIVE_KID at it and keep the old
+// file (private or public half) until tokens signed with it have expired.
+//
+// Without keys the service refuses to start when APP_ENV is "production";
+// otherwise an ephemeral Ed25519 key is generated for local development.
+func loadKeyRing() (*keyRing, error) {
+	ring := &keyRing{keys: map[string]*signingKey{}}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list JWT keys: %w", err)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+			key, err := loadSigningKey(path, kid)
+			if err != nil {
+				return nil, err
+			}
+			ring.keys[kid] = key
+			if key.private != nil {
+				ring.active = key
+			}
+		}
+	}
+
+	if kid := os.Getenv("JWT_ACTIVE_KID"); kid != "" {
+		key, ok := ring.keys[kid]
+		if !ok || key.private == nil {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID %q does not name a private key in JWT_KEYS_DIR", kid)
+		}
+		ring.active = key
+	}
+
+	if ring.active == nil {
+		if os.Getenv("APP_ENV") == "production" {
+			return nil, errors.New("no JWT signing key configured: set JWT_KEYS_DIR")
+		}
+
+		key, err := generateDevKey()
+		if err != nil {
+			return nil, err
+		}
+		ring.keys[key.kid] = key
+		ring.active = key
+	}
+
+	return ring, nil
+}
+
+// loadSigningKey parses a PEM file holding an RSA or Ed25519 private or public key
+func loadSigningKey(path, kid string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key %s is not PEM encoded", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("JWT key %s has unsupported PEM type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT key %s: %w", path, err)
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("JWT key %s must be an RSA or Ed25519 key", path)
+	}
+
+	return key, nil
+}
+
+// generateDevKey creates a throwaway Ed25519 key for local development
+func generateDevKey() (*signingKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate JWT key: %w", err)
+	}
+
+	suffix, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{
+		kid:     "dev-" + suffix,
+		method:  jwt.SigningMethodEdDSA,
+		private: private,
+		public:  public,
+	}, nil
+}
+
+// sign signs claims with the active key and sets the kid header
+func (k *keyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method, claims)
+	token.Header["kid"] = k.active.kid
+
+	tokenString, err := token.SignedString(k.active.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// keyFunc resolves the verification key from the token's kid header
+func (k *keyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+
+	// Verify signing method matches the key
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.public, nil
+}
+
+// parse verifies a token's signature and issuer against the ring and returns its claims
+func (k *keyRing) parse(tokenString, issuer string) (*AccessClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &AccessClaims{}, k.keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(issuer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*AccessClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// jwks returns the public halves of all keys as a JSON Web Key Set
+func (k *keyRing) jwks() *model.JWKSResponse {
+	kids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := &model.JWKSResponse{Keys: []model.JWK{}}
+	for _, kid := range kids {
+		key := k.keys[kid]
+		jwk := model.JWK{
+			Kid: kid,
+			Use: "sig",
+			Alg: key.method.Alg(),
+		}
+
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testIssuer = "university-api"
+
+// writePEM stores a DER block as <dir>/<kid>.pem
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadTestRing loads a key ring from dir with the given active kid
+func loadTestRing(t *testing.T, dir, activeKid string) *keyRing {
+	t.Helper()
+	t.Setenv("APP_ENV", "")
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_ACTIVE_KID", activeKid)
+
+	ring, err := loadKeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring
+}
+
+func testClaims(issuer string) AccessClaims {
+	now := time.Now()
+	return AccessClaims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
+
+// rotatedKeys sets up a key directory before and after rotating from an RSA
+// key to an Ed25519 key. After the rotation only the public half of the old
+// key is kept.
+func rotatedKeys(t *testing.T) (before, after *keyRing, oldKey *rsa.PrivateKey, newKey ed25519.PrivateKey) {
+	t.Helper()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, newKey, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	beforeDir := t.TempDir()
+	writePEM(t, beforeDir, "2025-01", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(oldKey))
+	before = loadTestRing(t, beforeDir, "")
+
+	oldPublic, err := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPrivate, err := x509.MarshalPKCS8PrivateKey(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afterDir := t.TempDir()
+	writePEM(t, afterDir, "2025-01", "PUBLIC KEY", oldPublic)
+	writePEM(t, afterDir, "2026-01", "PRIVATE KEY", newPrivate)
+	after = loadTestRing(t, afterDir, "")
+
+	return before, after, oldKey, newKey
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	before, after, _, _ := rotatedKeys(t)
+
+	if after.active.kid != "2026-01" {
+		t.Fatalf("active kid = %q, want the last private key 2026-01", after.active.kid)
+	}
+
+	previous, err := before.sign(testClaims(testIssuer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := after.sign(testClaims(testIssuer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"previous kid": previous, "current kid": current} {
+		claims, err := after.parse(token, testIssuer)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if claims.UserID != 7 {
+			t.Errorf("%s: user_id = %d, want 7", name, claims.UserID)
+		}
+	}
+
+	header, _, _ := jwt.NewParser().ParseUnverified(current, &AccessClaims{})
+	if header.Header["kid"] != "2026-01" || header.Header["alg"] != "EdDSA" {
+		t.Errorf("current token header = %v, want kid 2026-01 and alg EdDSA", header.Header)
+	}
+}
+
+func TestKeyRingRejectsForeignTokens(t *testing.T) {
+	_, ring, oldKey, newKey := rotatedKeys(t)
+
+	stranger, err := generateDevKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign := &keyRing{active: stranger, keys: map[string]*signingKey{stranger.kid: stranger}}
+	unknownKid, err := foreign.sign(testClaims(testIssuer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, testClaims(testIssuer))
+	none.Header["kid"] = "2026-01"
+	algNone, err := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// HS256 keyed with a public key, the classic algorithm confusion attack
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(testIssuer))
+	hmacToken.Header["kid"] = "2026-01"
+	hs256, err := hmacToken.SignedString([]byte(newKey.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// RS256 signed by the right key but presented under the Ed25519 kid
+	rsaToken := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims(testIssuer))
+	rsaToken.Header["kid"] = "2026-01"
+	wrongAlg, err := rsaToken.SignedString(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrongIssuer, err := ring.sign(testClaims("someone-else"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredClaims := testClaims(testIssuer)
+	expiredClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	expired, err := ring.sign(expiredClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"unknown kid":     unknownKid,
+		"alg none":        algNone,
+		"HS256":           hs256,
+		"alg not for kid": wrongAlg,
+		"wrong issuer":    wrongIssuer,
+		"expired":         expired,
+		"garbage":         "not.a.token",
+	}
+	for name, token := range tests {
+		if _, err := ring.parse(token, testIssuer); err == nil {
+			t.Errorf("%s: token was accepted", name)
+		}
+	}
+}
+
+func TestKeyRingJWKS(t *testing.T) {
+	_, ring, oldKey, newKey := rotatedKeys(t)
+
+	set := ring.jwks()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(set.Keys))
+	}
+
+	rsaJWK, edJWK := set.Keys[0], set.Keys[1]
+	if rsaJWK.Kid != "2025-01" || rsaJWK.Kty != "RSA" || rsaJWK.Alg != "RS256" || rsaJWK.Use != "sig" {
+		t.Errorf("RSA JWK = %+v", rsaJWK)
+	}
+	if edJWK.Kid != "2026-01" || edJWK.Kty != "OKP" || edJWK.Crv != "Ed25519" || edJWK.Alg != "EdDSA" || edJWK.Use != "sig" {
+		t.Errorf("Ed25519 JWK = %+v", edJWK)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).SetBytes(n).Cmp(oldKey.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(oldKey.E) {
+		t.Error("RSA JWK does not match the public key")
+	}
+	if rsaJWK.E != "AQAB" {
+		t.Errorf("RSA exponent = %q, want AQAB", rsaJWK.E)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(edJWK.X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.PublicKey(x).Equal(newKey.Public()) {
+		t.Error("Ed25519 JWK does not match the public key")
+	}
+}
+
+func TestLoadSigningKeyErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	notPEM := filepath.Join(dir, "broken.pem")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSigningKey(notPEM, "broken"); err == nil {
+		t.Error("accepted a file that is not PEM")
+	}
+
+	writePEM(t, dir, "cert", "CERTIFICATE", []byte{1, 2, 3})
+	if _, err := loadSigningKey(filepath.Join(dir, "cert.pem"), "cert"); err == nil {
+		t.Error("accepted an unsupported PEM type")
+	}
+}
+
+func TestLoadKeyRingConfiguration(t *testing.T) {
+	t.Setenv("JWT_KEYS_DIR", t.TempDir())
+	t.Setenv("JWT_ACTIVE_KID", "")
+
+	t.Setenv("APP_ENV", "production")
+	if _, err := loadKeyRing(); err == nil {
+		t.Error("production started without signing keys")
+	}
+
+	t.Setenv("APP_ENV", "")
+	ring, err := loadKeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring.active == nil || ring.active.private == nil {
+		t.Error("no development key was generated")
+	}
+
+	// rotatedKeys leaves JWT_KEYS_DIR at the rotated directory, which only
+	// holds the public half of 2025-01
+	rotatedKeys(t)
+	t.Setenv("JWT_ACTIVE_KID", "2025-01")
+	if _, err := loadKeyRing(); err == nil {
+		t.Error("a public-only key was accepted as the active key")
+	}
+}
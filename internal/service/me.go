@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrNoProfile is returned when no student or staff record is linked to the account
+	ErrNoProfile = errors.New("no student or staff record is linked to this account")
+	// ErrNotAStudent is returned for student-only data when the account is not linked to a student
+	ErrNotAStudent = errors.New("no student record is linked to this account")
+)
+
+// GetMyProfile returns the student or staff record linked to the user account
+func (s *Service) GetMyProfile(userID int) (*model.ProfileResponse, error) {
+	user, err := s.repo.GetUserByID(strconv.Itoa(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &model.ProfileResponse{Email: user.Email, Roles: user.Roles}
+
+	student, err := s.repo.GetStudentByUserID(userID)
+	if err == nil {
+		profile.Type = "student"
+		profile.Student = student
+		return profile, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	staff, err := s.repo.GetStaffByUserID(userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoProfile
+		}
+		return nil, err
+	}
+	profile.Type = "staff"
+	profile.Staff = staff
+	return profile, nil
+}
+
+// GetMySchedule returns the group schedule for students and the faculty schedule for staff
+func (s *Service) GetMySchedule(userID int) ([]model.ScheduleResponse, error) {
+	student, err := s.repo.GetStudentByUserID(userID)
+	if err == nil {
+		return s.repo.GetGroupSchedule(strconv.Itoa(student.GroupID))
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	staff, err := s.repo.GetStaffByUserID(userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoProfile
+		}
+		return nil, err
+	}
+	return s.repo.GetFacultySchedule(staff.FacultyID)
+}
+
+// GetMyAttendance returns the attendance records of the student linked to the account
+func (s *Service) GetMyAttendance(userID int) ([]model.AttendanceRecord, error) {
+	student, err := s.myStudent(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetStudentAttendance(student.ID)
+}
+
+// GetMyGrades returns the grades of the student linked to the account
+func (s *Service) GetMyGrades(userID int) ([]model.GradeResponse, error) {
+	student, err := s.myStudent(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetStudentGrades(student.ID)
+}
+
+// myStudent resolves the student record linked to the account
+func (s *Service) myStudent(userID int) (*model.StudentProfile, error) {
+	student, err := s.repo.GetStudentByUserID(userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotAStudent
+		}
+		return nil, err
+	}
+	return student, nil
+}
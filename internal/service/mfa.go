@@ -0,0 +1,376 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrInvalidMFAChallenge is returned when an mfa_token is unknown, expired or out of attempts
+	ErrInvalidMFAChallenge = errors.New("invalid or expired two-factor login, sign in again")
+	// ErrInvalidMFACode is returned when a TOTP or recovery code does not match
+	ErrInvalidMFACode = errors.New("invalid two-factor code")
+	// ErrMFAAlreadyEnabled is returned when enrolling a user who already uses two-factor authentication
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrMFANotEnabled is returned for operations that need a confirmed enrollment
+	ErrMFANotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrMFARequired is returned when a user tries to disable two-factor authentication their role requires
+	ErrMFARequired = errors.New("two-factor authentication is required for your role")
+)
+
+// recoveryCodeCount is the number of recovery codes issued at a time
+const recoveryCodeCount = 10
+
+// mfaPolicy configures two-factor authentication
+type mfaPolicy struct {
+	requiredRoles []string      // roles that must use two-factor authentication
+	issuer        string        // issuer shown in authenticator apps
+	challengeTTL  time.Duration // lifetime of an mfa_token
+	maxAttempts   int           // wrong codes allowed per mfa_token
+}
+
+func mfaPolicyFromEnv() mfaPolicy {
+	var roles []string
+	for _, role := range strings.Split(os.Getenv("MFA_REQUIRED_ROLES"), ",") {
+		if role = strings.ToUpper(strings.TrimSpace(role)); role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "University"
+	}
+
+	return mfaPolicy{
+		requiredRoles: roles,
+		issuer:        issuer,
+		challengeTTL:  durationFromEnv("MFA_CHALLENGE_TTL", 5*time.Minute),
+		maxAttempts:   intFromEnv("MFA_MAX_ATTEMPTS", 5),
+	}
+}
+
+// completeLogin issues tokens for a user whose password was accepted, or a
+// pending mfa_token when the user has or needs a second factor
+func (s *Service) completeLogin(user *model.User, client model.ClientInfo) (*model.LoginResponse, error) {
+	_, enabled, err := s.repo.GetUserMFA(user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+	if enabled {
+		return s.startMFAChallenge(user.ID, false)
+	}
+
+	required, err := s.mfaRequired(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		return s.startMFAChallenge(user.ID, true)
+	}
+
+	return s.issueTokens(user, client)
+}
+
+// startMFAChallenge creates a short-lived mfa_token for the second login step
+func (s *Service) startMFAChallenge(userID int, enrollmentRequired bool) (*model.LoginResponse, error) {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateMFAChallenge(userID, tokenHash, s.mfa.challengeTTL); err != nil {
+		return nil, fmt.Errorf("failed to store two-factor login: %w", err)
+	}
+
+	return &model.LoginResponse{
+		ExpiresIn:             int64(s.mfa.challengeTTL.Seconds()),
+		MFARequired:           true,
+		MFAToken:              token,
+		MFAEnrollmentRequired: enrollmentRequired,
+	}, nil
+}
+
+// VerifyMFA completes a login with a TOTP or recovery code. For users who had
+// to enroll during login, the first valid code also confirms the enrollment and
+// the response carries their recovery codes.
+func (s *Service) VerifyMFA(req *model.MFAVerifyRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	if req.MFAToken == "" || req.Code == "" {
+		return nil, errors.New("mfa_token and code are required")
+	}
+
+	challengeHash := hashToken(req.MFAToken)
+	userID, err := s.repo.GetMFAChallenge(challengeHash, s.mfa.maxAttempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidMFAChallenge
+		}
+		return nil, fmt.Errorf("failed to load two-factor login: %w", err)
+	}
+
+	user, err := s.repo.GetUserAccountByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive {
+		return nil, errors.New("user account is inactive")
+	}
+
+	secret, enabled, err := s.repo.GetUserMFA(userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("start two-factor enrollment first")
+		}
+		return nil, fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+
+	var recoveryCodes []string
+	ok := false
+	if enabled {
+		ok, err = s.checkSecondFactor(userID, secret, req.Code)
+		if err != nil {
+			return nil, err
+		}
+	} else if step, valid := verifyTOTP(secret, req.Code, time.Now()); valid {
+		recoveryCodes, err = s.enableMFA(userID, step)
+		if err != nil {
+			return nil, err
+		}
+		ok = true
+	}
+
+	if !ok {
+		if err := s.repo.FailMFAChallenge(challengeHash); err != nil {
+			return nil, fmt.Errorf("failed to record two-factor attempt: %w", err)
+		}
+		return nil, ErrInvalidMFACode
+	}
+
+	if err := s.repo.DeleteMFAChallenge(challengeHash); err != nil {
+		return nil, fmt.Errorf("failed to complete two-factor login: %w", err)
+	}
+
+	response, err := s.issueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// EnrollMFA generates a new TOTP secret for the user. It takes effect once
+// confirmed with a code from the authenticator app.
+func (s *Service) EnrollMFA(userID int) (*model.MFAEnrollResponse, error) {
+	_, enabled, err := s.repo.GetUserMFA(userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	user, err := s.repo.GetUserAccountByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetPendingMFASecret(userID, secret); err != nil {
+		return nil, fmt.Errorf("failed to store two-factor secret: %w", err)
+	}
+
+	return &model.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totpURI(s.mfa.issuer, user.Email, secret),
+	}, nil
+}
+
+// EnrollMFAForLogin starts enrollment for a user whose login is waiting on a
+// second factor their role requires
+func (s *Service) EnrollMFAForLogin(req *model.MFAEnrollRequest) (*model.MFAEnrollResponse, error) {
+	userID, err := s.repo.GetMFAChallenge(hashToken(req.MFAToken), s.mfa.maxAttempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidMFAChallenge
+		}
+		return nil, fmt.Errorf("failed to load two-factor login: %w", err)
+	}
+
+	return s.EnrollMFA(userID)
+}
+
+// ConfirmMFA enables two-factor authentication with a code from the
+// authenticator app and returns the user's recovery codes
+func (s *Service) ConfirmMFA(userID int, code string) (*model.RecoveryCodesResponse, error) {
+	secret, enabled, err := s.repo.GetUserMFA(userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("start two-factor enrollment first")
+		}
+		return nil, fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := verifyTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := s.enableMFA(userID, step)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current code
+func (s *Service) RegenerateRecoveryCodes(userID int, code string) (*model.RecoveryCodesResponse, error) {
+	if err := s.checkEnabledSecondFactor(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA turns off two-factor authentication after checking a current code.
+// Users whose role requires it cannot turn it off.
+func (s *Service) DisableMFA(userID int, code string) error {
+	required, err := s.mfaRequired(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+
+	if err := s.checkEnabledSecondFactor(userID, code); err != nil {
+		return err
+	}
+
+	return s.repo.DisableMFA(userID)
+}
+
+// ResetMFA removes a user's two-factor setup, e.g. after a lost device.
+// Users whose role requires it have to enroll again on their next login.
+func (s *Service) ResetMFA(userID int) error {
+	return s.repo.DisableMFA(userID)
+}
+
+// mfaRequired reports whether any of the user's roles requires two-factor authentication
+func (s *Service) mfaRequired(userID int) (bool, error) {
+	if len(s.mfa.requiredRoles) == 0 {
+		return false, nil
+	}
+
+	roles, err := s.repo.GetUserRoles(strconv.Itoa(userID))
+	if err != nil {
+		return false, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	for _, role := range roles {
+		if slices.Contains(s.mfa.requiredRoles, role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// enableMFA confirms enrollment at the given TOTP step and returns fresh recovery codes
+func (s *Service) enableMFA(userID int, step int64) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.EnableMFA(userID, step, hashes); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	return codes, nil
+}
+
+// checkEnabledSecondFactor verifies a code for a user with confirmed two-factor authentication
+func (s *Service) checkEnabledSecondFactor(userID int, code string) error {
+	secret, enabled, err := s.repo.GetUserMFA(userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+	if !enabled {
+		return ErrMFANotEnabled
+	}
+
+	ok, err := s.checkSecondFactor(userID, secret, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// checkSecondFactor accepts a TOTP code that was not used before, or an unused
+// recovery code, and consumes it
+func (s *Service) checkSecondFactor(userID int, secret, code string) (bool, error) {
+	if step, ok := verifyTOTP(secret, code, time.Now()); ok {
+		fresh, err := s.repo.UseTOTPStep(userID, step)
+		if err != nil {
+			return false, fmt.Errorf("failed to record two-factor code: %w", err)
+		}
+		return fresh, nil
+	}
+
+	err := s.repo.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	return true, nil
+}
+
+// newRecoveryCodes returns recovery codes formatted as xxxxx-xxxxx and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := randomHex(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode strips separators and case so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
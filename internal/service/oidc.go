@@ -0,0 +1,480 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"university/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrOIDCDisabled is returned when single sign-on is not configured
+	ErrOIDCDisabled = errors.New("single sign-on is not configured")
+	// ErrInvalidOIDCState is returned when the callback state is unknown, used or expired
+	ErrInvalidOIDCState = errors.New("invalid or expired login state")
+)
+
+// oidcStateTTL bounds how long a user may take to sign in at the identity provider
+const oidcStateTTL = 10 * time.Minute
+
+// oidcProvider is an OpenID Connect relying party for the university IdP.
+// Discovery metadata and signing keys are fetched lazily and cached.
+type oidcProvider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       string
+	client       *http.Client
+
+	mu          sync.Mutex
+	metadata    *oidcMetadata
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// oidcMetadata is the subset of the discovery document the login flow needs
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idTokenClaims are the ID token claims used to identify the user
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// loadOIDCProvider configures single sign-on from OIDC_ISSUER, OIDC_CLIENT_ID,
+// OIDC_CLIENT_SECRET, OIDC_REDIRECT_URL and OIDC_SCOPES. It returns nil when
+// OIDC_ISSUER is unset.
+func loadOIDCProvider(apiBaseURL string) (*oidcProvider, error) {
+	issuer := strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/")
+	if issuer == "" {
+		return nil, nil
+	}
+
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	if clientID == "" {
+		return nil, errors.New("OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+	}
+
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = apiBaseURL + "/api/auth/oidc/callback"
+	}
+
+	scopes := os.Getenv("OIDC_SCOPES")
+	if scopes == "" {
+		scopes = "openid email profile"
+	}
+
+	return &oidcProvider{
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// OIDCLoginURL starts a single sign-on login and returns the identity provider
+// URL to redirect the browser to. The login is bound to a one-time state, a
+// nonce and a PKCE code verifier kept server-side.
+func (s *Service) OIDCLoginURL() (string, error) {
+	if s.oidc == nil {
+		return "", ErrOIDCDisabled
+	}
+
+	metadata, err := s.oidc.discover()
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, _, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.CreateOIDCState(state, codeVerifier, nonce, oidcStateTTL); err != nil {
+		return "", fmt.Errorf("failed to store login state: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.oidc.clientID},
+		"redirect_uri":          {s.oidc.redirectURL},
+		"scope":                 {s.oidc.scopes},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// OIDCCallback completes a single sign-on login: it redeems the authorization
+// code, verifies the ID token and issues our own tokens for the mapped user.
+func (s *Service) OIDCCallback(code, state string, client model.ClientInfo) (*model.LoginResponse, error) {
+	if s.oidc == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	if code == "" || state == "" {
+		return nil, errors.New("code and state are required")
+	}
+
+	codeVerifier, nonce, err := s.repo.ConsumeOIDCState(state)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, fmt.Errorf("failed to load login state: %w", err)
+	}
+
+	rawIDToken, err := s.oidc.exchange(code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.oidc.verifyIDToken(rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveOIDCUser(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("user account is inactive")
+	}
+
+	// Single sign-on is held to the same verification rule as password logins
+	if s.requireEmailVerification && user.EmailVerifiedAt == nil {
+		return nil, errors.New("email address is not verified")
+	}
+
+	// The two-factor policy applies to single sign-on logins as well
+	return s.completeLogin(user, client)
+}
+
+// resolveOIDCUser maps an identity to a user: by linked subject first, then by
+// verified email, and provisions a new account on first login
+func (s *Service) resolveOIDCUser(claims *idTokenClaims) (*model.User, error) {
+	issuer, subject := s.oidc.issuer, claims.Subject
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+
+	user, err := s.repo.GetUserByIdentity(issuer, subject)
+	if err == nil {
+		if err := s.repo.LinkIdentity(user.ID, issuer, subject, email); err != nil {
+			return nil, fmt.Errorf("failed to record login: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if email == "" {
+		return nil, errors.New("identity provider did not return an email address")
+	}
+
+	// Link an existing local account only when the IdP vouches for the email
+	user, err = s.repo.GetUserByEmail(email)
+	if err == nil {
+		if !claims.EmailVerified {
+			return nil, errors.New("an account with this email already exists")
+		}
+		if err := s.repo.LinkIdentity(user.ID, issuer, subject, email); err != nil {
+			return nil, fmt.Errorf("failed to link identity: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// An unverified email could belong to someone else, who would later take
+	// the account over through a password reset
+	if !claims.EmailVerified {
+		return nil, errors.New("email address is not verified")
+	}
+
+	user, err = s.repo.CreateOIDCUser(email, issuer, subject, claims.EmailVerified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// discover fetches and caches the provider's discovery document
+func (p *oidcProvider) discover() (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata oidcMetadata
+	if err := p.getJSON(p.issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("failed to load OIDC discovery document: %w", err)
+	}
+	if strings.TrimRight(metadata.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer %q does not match %q", metadata.Issuer, p.issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing endpoints")
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// exchange redeems an authorization code at the token endpoint and returns the raw ID token
+func (p *oidcProvider) exchange(code, codeVerifier string) (string, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"client_id":     {p.clientID},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("identity provider rejected the authorization code: %s",
+			strings.TrimSpace(body.Error+" "+body.ErrorDescription))
+	}
+	if body.IDToken == "" {
+		return "", errors.New("identity provider did not return an ID token")
+	}
+
+	return body.IDToken, nil
+}
+
+// verifyIDToken checks the ID token signature, issuer, audience, expiry and nonce
+func (p *oidcProvider) verifyIDToken(rawIDToken, nonce string) (*idTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(rawIDToken, &idTokenClaims{}, p.keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*idTokenClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid ID token claims")
+	}
+
+	if claims.Nonce != nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	return claims, nil
+}
+
+// keyFunc resolves the IdP signing key by kid, refetching the key set at most
+// once a minute so provider key rotations are picked up
+func (p *oidcProvider) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	metadata, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.lookupKey(kid)
+	if !ok && time.Since(p.keysFetched) > time.Minute {
+		if err := p.fetchKeys(metadata.JWKSURI); err != nil {
+			return nil, err
+		}
+		key, ok = p.lookupKey(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown ID token signing key: %q", kid)
+	}
+
+	return key, nil
+}
+
+// lookupKey finds a cached key by kid; tokens without a kid match a sole key
+func (p *oidcProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// fetchKeys replaces the cached key set with the provider's current JWKS.
+// Keys of unsupported types are skipped.
+func (p *oidcProvider) fetchKeys(jwksURI string) error {
+	var set model.JWKSResponse
+	if err := p.getJSON(jwksURI, &set); err != nil {
+		return fmt.Errorf("failed to load OIDC signing keys: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := parseJWK(jwk); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	p.keys = keys
+	p.keysFetched = time.Now()
+	return nil
+}
+
+// getJSON fetches a URL and decodes its JSON body into v
+func (p *oidcProvider) getJSON(url string, v interface{}) error {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// parseJWK converts an RSA, EC or Ed25519 JSON Web Key into a public key
+func parseJWK(jwk model.JWK) (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
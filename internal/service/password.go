@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"university/internal/mail"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrInvalidResetToken is returned when a password reset token is unknown, used or expired
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	// ErrWrongPassword is returned when the current password given to ChangePassword does not match
+	ErrWrongPassword = errors.New("current password is incorrect")
+	// ErrEmailRequired is returned when a request that needs an email has none
+	ErrEmailRequired = errors.New("email is required")
+	// ErrInvalidEmailFormat is returned when an email address is malformed
+	ErrInvalidEmailFormat = errors.New("invalid email format")
+)
+
+// ForgotPassword emails a single-use password reset link to the account owner.
+// It does not reveal whether an account exists for the given email.
+func (s *Service) ForgotPassword(req *model.ForgotPasswordRequest) error {
+	if req.Email == "" {
+		return ErrEmailRequired
+	}
+	if !isValidEmail(req.Email) {
+		return ErrInvalidEmailFormat
+	}
+
+	user, err := s.repo.GetUserByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.IsActive {
+		return nil
+	}
+
+	return s.sendPasswordResetEmail(user)
+}
+
+// sendPasswordResetEmail creates a reset token and emails the reset link to the user
+func (s *Service) sendPasswordResetEmail(user *model.User) error {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.CreatePasswordResetToken(user.ID, tokenHash, s.passwordResetTTL); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appBaseURL, url.QueryEscape(token))
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"We received a request to reset your password.\n\n"+
+				"Use the link below to choose a new one. It expires in %s and can be used once.\n\n%s\n\n"+
+				"If you did not request this, you can ignore this email.\n",
+			s.passwordResetTTL, link,
+		),
+	}
+	return s.mailer.Send(msg)
+}
+
+// ResetPassword sets a new password using a reset token and signs the user
+// out of all existing sessions.
+func (s *Service) ResetPassword(req *model.ResetPasswordRequest) error {
+	if req.Token == "" {
+		return errors.New("token is required")
+	}
+
+	if err := s.validatePassword(req.NewPassword, ""); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.ResetPassword(hashToken(req.Token), hashedPassword); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password of a logged-in user after checking the
+// current one. All sessions are signed out and fresh tokens are returned for
+// the caller. Wrong current passwords count as failed logins.
+func (s *Service) ChangePassword(userID int, req *model.ChangePasswordRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	if req.CurrentPassword == "" {
+		return nil, errors.New("current password is required")
+	}
+
+	user, err := s.repo.GetUserAccountByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.checkLoginLock(user.Email, client.IP); err != nil {
+		return nil, err
+	}
+
+	if ok, _ := s.passwords.verify(user.PasswordHash, req.CurrentPassword); !ok {
+		if err := s.recordLoginFailure(user.Email, client.IP); err != nil {
+			return nil, err
+		}
+		return nil, ErrWrongPassword
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return nil, errors.New("new password must differ from the current one")
+	}
+
+	if err := s.validatePassword(req.NewPassword, user.Email); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ChangePassword(userID, hashedPassword); err != nil {
+		return nil, fmt.Errorf("failed to change password: %w", err)
+	}
+	user.PasswordHash = hashedPassword
+
+	return s.issueTokens(user, client)
+}
+
+// validatePassword checks that a password satisfies the password policy
+func (s *Service) validatePassword(password, email string) error {
+	if err := s.passwordPolicy.validate(password, email); err != nil {
+		return err
+	}
+
+	// bcrypt only looks at the first 72 bytes and rejects longer input
+	if s.passwords.algorithm == "bcrypt" && len(password) > 72 {
+		return errors.New("password must be at most 72 bytes")
+	}
+
+	return nil
+}
+
+// hashPassword hashes a password with the configured algorithm
+func (s *Service) hashPassword(password string) (string, error) {
+	return s.passwords.hash(password)
+}
+
+// checkPassword verifies a login password and transparently upgrades hashes
+// made with an older algorithm or cost
+func (s *Service) checkPassword(user *model.User, password string) (bool, error) {
+	ok, outdated := s.passwords.verify(user.PasswordHash, password)
+	if !ok || !outdated {
+		return ok, nil
+	}
+
+	upgraded, err := s.hashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	if err := s.repo.UpgradePasswordHash(user.ID, user.PasswordHash, upgraded); err != nil {
+		return false, fmt.Errorf("failed to upgrade password hash: %w", err)
+	}
+	user.PasswordHash = upgraded
+
+	return true, nil
+}
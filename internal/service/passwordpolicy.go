@@ -0,0 +1,279 @@
+package service
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordPolicy holds the rules new passwords must satisfy
+type passwordPolicy struct {
+	minLength     int
+	maxLength     int
+	requireUpper  bool
+	requireLower  bool
+	requireDigit  bool
+	requireSymbol bool
+	breached      map[string]struct{} // upper-case hex SHA-1 of known breached passwords
+}
+
+// passwordPolicyFromEnv reads PASSWORD_MIN_LENGTH, PASSWORD_MAX_LENGTH,
+// PASSWORD_REQUIRE_{UPPER,LOWER,DIGIT,SYMBOL} and PASSWORD_BREACHED_LIST.
+// The breached list is a local file with one password per line, or one SHA-1
+// hash per line in the "HASH" or "HASH:count" format of published breach corpora.
+func passwordPolicyFromEnv() (passwordPolicy, error) {
+	policy := passwordPolicy{
+		minLength:     intFromEnv("PASSWORD_MIN_LENGTH", 8),
+		maxLength:     intFromEnv("PASSWORD_MAX_LENGTH", 128),
+		requireUpper:  os.Getenv("PASSWORD_REQUIRE_UPPER") == "true",
+		requireLower:  os.Getenv("PASSWORD_REQUIRE_LOWER") == "true",
+		requireDigit:  os.Getenv("PASSWORD_REQUIRE_DIGIT") == "true",
+		requireSymbol: os.Getenv("PASSWORD_REQUIRE_SYMBOL") == "true",
+	}
+
+	if path := os.Getenv("PASSWORD_BREACHED_LIST"); path != "" {
+		breached, err := loadBreachedPasswords(path)
+		if err != nil {
+			return passwordPolicy{}, err
+		}
+		policy.breached = breached
+	}
+
+	return policy, nil
+}
+
+// loadBreachedPasswords reads a breached password list into a set of SHA-1 hashes
+func loadBreachedPasswords(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	breached := map[string]struct{}{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			breached[strings.ToUpper(hash)] = struct{}{}
+			continue
+		}
+		breached[sha1Hex(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return breached, nil
+}
+
+// validate checks a new password against the policy. The account email, when
+// known, may not be used as or inside the password.
+func (p passwordPolicy) validate(password, email string) error {
+	if password == "" {
+		return errors.New("password is required")
+	}
+
+	length := len([]rune(password))
+	if length < p.minLength {
+		return fmt.Errorf("password must be at least %d characters", p.minLength)
+	}
+	if p.maxLength > 0 && length > p.maxLength {
+		return fmt.Errorf("password must be at most %d characters", p.maxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if p.requireUpper && !upper {
+		missing = append(missing, "an upper-case letter")
+	}
+	if p.requireLower && !lower {
+		missing = append(missing, "a lower-case letter")
+	}
+	if p.requireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.requireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("password must contain %s", strings.Join(missing, ", "))
+	}
+
+	if email != "" {
+		local, _, _ := strings.Cut(strings.ToLower(email), "@")
+		if len(local) >= 3 && strings.Contains(strings.ToLower(password), local) {
+			return errors.New("password must not contain your email address")
+		}
+	}
+
+	if _, ok := p.breached[sha1Hex(password)]; ok {
+		return errors.New("password appears in a list of breached passwords, choose another one")
+	}
+
+	return nil
+}
+
+// passwordHasher hashes passwords with the configured algorithm and cost
+type passwordHasher struct {
+	algorithm     string // "bcrypt" or "argon2id"
+	bcryptCost    int
+	argon2Time    uint32
+	argon2Memory  uint32 // KiB
+	argon2Threads uint8
+}
+
+// argon2id output sizes
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// passwordHasherFromEnv reads PASSWORD_HASH_ALGORITHM (bcrypt or argon2id),
+// PASSWORD_BCRYPT_COST and PASSWORD_ARGON2_{TIME,MEMORY_KIB,THREADS}.
+// Changing them upgrades existing hashes on the users' next login.
+func passwordHasherFromEnv() (passwordHasher, error) {
+	hasher := passwordHasher{
+		algorithm:     strings.ToLower(os.Getenv("PASSWORD_HASH_ALGORITHM")),
+		bcryptCost:    intFromEnv("PASSWORD_BCRYPT_COST", bcrypt.DefaultCost),
+		argon2Time:    uint32(intFromEnv("PASSWORD_ARGON2_TIME", 3)),
+		argon2Memory:  uint32(intFromEnv("PASSWORD_ARGON2_MEMORY_KIB", 64*1024)),
+		argon2Threads: uint8(intFromEnv("PASSWORD_ARGON2_THREADS", 2)),
+	}
+
+	switch hasher.algorithm {
+	case "":
+		hasher.algorithm = "bcrypt"
+	case "bcrypt", "argon2id":
+	default:
+		return passwordHasher{}, fmt.Errorf("unsupported PASSWORD_HASH_ALGORITHM %q", hasher.algorithm)
+	}
+
+	if hasher.bcryptCost < bcrypt.MinCost || hasher.bcryptCost > bcrypt.MaxCost {
+		return passwordHasher{}, fmt.Errorf("PASSWORD_BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	if hasher.argon2Time == 0 || hasher.argon2Memory == 0 || hasher.argon2Threads == 0 {
+		return passwordHasher{}, errors.New("PASSWORD_ARGON2_TIME, PASSWORD_ARGON2_MEMORY_KIB and PASSWORD_ARGON2_THREADS must be positive")
+	}
+
+	return hasher, nil
+}
+
+// hash hashes a password with the configured algorithm
+func (h passwordHasher) hash(password string) (string, error) {
+	if h.algorithm == "argon2id" {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+		key := argon2.IDKey([]byte(password), salt, h.argon2Time, h.argon2Memory, h.argon2Threads, argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, h.argon2Memory, h.argon2Time, h.argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hashed), nil
+}
+
+// verify checks a password against a bcrypt or argon2id hash. It also reports
+// whether the hash was made with other settings than the configured ones and
+// should be replaced.
+func (h passwordHasher) verify(hash, password string) (bool, bool) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		parts := strings.Split(hash, "$")
+		if len(parts) != 6 {
+			return false, false
+		}
+
+		var version int
+		if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+			return false, false
+		}
+
+		var memory, iterations uint32
+		var threads uint8
+		if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+			return false, false
+		}
+		// argon2 panics on zero threads and degenerates on zero cost
+		if memory == 0 || iterations == 0 || threads == 0 {
+			return false, false
+		}
+
+		salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+		if err != nil {
+			return false, false
+		}
+		key, err := base64.RawStdEncoding.DecodeString(parts[5])
+		if err != nil || len(key) == 0 {
+			return false, false
+		}
+
+		computed := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, false
+		}
+
+		outdated := h.algorithm != "argon2id" ||
+			memory != h.argon2Memory || iterations != h.argon2Time || threads != h.argon2Threads
+		return true, outdated
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	outdated := h.algorithm != "bcrypt" || err != nil || cost != h.bcryptCost
+	return true, outdated
+}
+
+// isSHA1Hex reports whether s is a hex-encoded SHA-1 hash
+func isSHA1Hex(s string) bool {
+	if len(s) != sha1.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// sha1Hex returns the upper-case hex SHA-1 of s, the format used by breach corpora
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := passwordPolicy{
+		minLength:     8,
+		maxLength:     16,
+		requireUpper:  true,
+		requireDigit:  true,
+		requireSymbol: true,
+		breached:      map[string]struct{}{sha1Hex("Password1!"): {}},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		wantErr  string
+	}{
+		{"valid", "Tr0ub4dor&3", "anna@university.kz", ""},
+		{"empty", "", "", "password is required"},
+		{"too short", "Ab1!", "", "at least 8 characters"},
+		{"too long", "Abcdefgh1!abcdefg", "", "at most 16 characters"},
+		{"length counts runes", "Пароль12!", "", ""},
+		{"missing classes", "abcdefgh", "", "an upper-case letter, a digit, a symbol"},
+		{"contains email", "Xanna-2024!", "Anna@university.kz", "must not contain your email"},
+		{"short local part ignored", "Xal-2024!", "al@university.kz", ""},
+		{"breached", "Password1!", "", "breached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.validate(tt.password, tt.email)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate(%q) = %v, want nil", tt.password, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate(%q) = %v, want error containing %q", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Low costs keep the tests fast; they are not meant for production
+var (
+	testBcrypt = passwordHasher{algorithm: "bcrypt", bcryptCost: bcrypt.MinCost, argon2Time: 1, argon2Memory: 64, argon2Threads: 1}
+	testArgon2 = passwordHasher{algorithm: "argon2id", bcryptCost: bcrypt.MinCost, argon2Time: 1, argon2Memory: 64, argon2Threads: 1}
+)
+
+func TestPasswordHasherRoundTrip(t *testing.T) {
+	for _, hasher := range []passwordHasher{testBcrypt, testArgon2} {
+		t.Run(hasher.algorithm, func(t *testing.T) {
+			hash, err := hasher.hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ok, outdated := hasher.verify(hash, "correct horse")
+			if !ok || outdated {
+				t.Fatalf("verify(correct) = %v, %v, want true, false", ok, outdated)
+			}
+
+			ok, outdated = hasher.verify(hash, "wrong horse")
+			if ok || outdated {
+				t.Fatalf("verify(wrong) = %v, %v, want false, false", ok, outdated)
+			}
+		})
+	}
+
+	hash, err := testArgon2.hash("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("argon2id hash %q does not use the PHC format", hash)
+	}
+}
+
+func TestPasswordHasherUpgrade(t *testing.T) {
+	bcryptHash, err := testBcrypt.hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2Hash, err := testArgon2.hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	costlierBcrypt := testBcrypt
+	costlierBcrypt.bcryptCost++
+	costlierArgon2 := testArgon2
+	costlierArgon2.argon2Time++
+
+	tests := []struct {
+		name   string
+		hasher passwordHasher
+		hash   string
+		want   bool
+	}{
+		{"bcrypt current", testBcrypt, bcryptHash, false},
+		{"bcrypt cost raised", costlierBcrypt, bcryptHash, true},
+		{"bcrypt to argon2id", testArgon2, bcryptHash, true},
+		{"argon2id current", testArgon2, argon2Hash, false},
+		{"argon2id cost raised", costlierArgon2, argon2Hash, true},
+		{"argon2id to bcrypt", testBcrypt, argon2Hash, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, outdated := tt.hasher.verify(tt.hash, "secret")
+			if !ok {
+				t.Fatal("verify failed for the correct password")
+			}
+			if outdated != tt.want {
+				t.Fatalf("outdated = %v, want %v", outdated, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordHasherRejectsMalformedArgon2(t *testing.T) {
+	// Salt "c2FsdHNhbHQ" is "saltsalt", key "a2V5a2V5" is "keykey"
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"missing fields", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ"},
+		{"wrong version", "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5"},
+		{"bad parameters", "$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5"},
+		{"zero threads", "$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHQ$a2V5a2V5"},
+		{"zero memory", "$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5"},
+		{"zero iterations", "$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5"},
+		{"bad salt", "$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5a2V5"},
+		{"bad key", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$!!!"},
+		{"empty key", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$"},
+		{"not a hash", "hashed_admin_password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, outdated := testArgon2.verify(tt.hash, "keykey")
+			if ok || outdated {
+				t.Fatalf("verify = %v, %v, want false, false", ok, outdated)
+			}
+		})
+	}
+}
+
+func TestPasswordHasherFromEnvRejectsZeroArgon2Threads(t *testing.T) {
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "argon2id")
+	t.Setenv("PASSWORD_ARGON2_THREADS", "256") // overflows uint8 to zero
+
+	if _, err := passwordHasherFromEnv(); err == nil {
+		t.Fatal("passwordHasherFromEnv accepted zero argon2 threads")
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"university/internal/model"
+)
+
+// Search result limits
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search finds students, staff, groups and subjects by name, email or title.
+// types narrows the result types; results carry highlighted copies of their
+// title and subtitle.
+func (s *Service) Search(query string, types []string, limit int) ([]model.SearchResult, error) {
+	query = cleanSearchQuery(query)
+	if len([]rune(query)) < 2 {
+		return nil, errors.New("q must be at least 2 characters")
+	}
+
+	for _, t := range types {
+		switch t {
+		case model.SearchStudent, model.SearchStaff, model.SearchGroup, model.SearchSubject:
+		default:
+			return nil, fmt.Errorf("unknown result type: %s", t)
+		}
+	}
+
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	results, err := s.repo.Search(query, types, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	terms := searchTerms(query)
+	for i := range results {
+		results[i].HighlightedTitle = highlight(results[i].Title, terms)
+		results[i].HighlightedSubtitle = highlight(results[i].Subtitle, terms)
+	}
+	return results, nil
+}
+
+// cleanSearchQuery keeps the letters, digits and the characters of emails,
+// so user input cannot act as a LIKE wildcard
+func cleanSearchQuery(query string) string {
+	query = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '@', r == '.', r == '-':
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return -1
+	}, query)
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// searchTransliteration mirrors the search_key database function, which folds
+// Cyrillic letters to Latin
+var searchTransliteration = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ә': "a", 'ғ': "g", 'қ': "k", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u",
+	'һ': "h", 'і': "i",
+}
+
+// searchKey folds a word the way search_key does in the database
+func searchKey(word string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if latin, ok := searchTransliteration[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// searchTerms returns the search keys of the words of a query
+func searchTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(query, isWordSeparator) {
+		if key := searchKey(word); key != "" {
+			terms = append(terms, key)
+		}
+	}
+	return terms
+}
+
+// highlight HTML-escapes text and wraps the words that match a term in <mark>
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	word := []rune{}
+
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		escaped := html.EscapeString(string(word))
+		if matchesTerm(searchKey(string(word)), terms) {
+			escaped = "<mark>" + escaped + "</mark>"
+		}
+		b.WriteString(escaped)
+		word = word[:0]
+	}
+
+	for _, r := range text {
+		if isWordSeparator(r) {
+			flush()
+			b.WriteString(html.EscapeString(string(r)))
+			continue
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	return b.String()
+}
+
+// matchesTerm reports whether a word contains a term, is a long enough part of
+// one, or differs from it by a typo or two
+func matchesTerm(key string, terms []string) bool {
+	for _, term := range terms {
+		switch {
+		case strings.Contains(key, term):
+			return true
+		case len(key) >= 3 && strings.Contains(term, key):
+			return true
+		case len(term) >= 4 && editDistance(key, term) <= len(term)/4:
+			return true
+		}
+	}
+	return false
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
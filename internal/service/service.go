@@ -5,30 +5,113 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 	"time"
+	"university/internal/blob"
+	"university/internal/mail"
 	"university/internal/model"
 	"university/internal/storage"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 )
 
 type Service struct {
-	repo      *storage.Repository
-	jwtSecret string
+	repo             *storage.Repository
+	mailer           mail.Sender
+	logger           *zap.SugaredLogger
+	keys             *keyRing
+	jwtIssuer        string
+	appBaseURL       string
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+	passwordResetTTL time.Duration
+	impersonationTTL time.Duration
+	invitationTTL    time.Duration
+
+	emailVerificationTTL     time.Duration
+	requireEmailVerification bool
+
+	throttle loginThrottle
+	mfa      mfaPolicy
+
+	passwordPolicy passwordPolicy
+	passwords      passwordHasher
+
+	oidc *oidcProvider
+
+	blobs           blob.Store
+	documentMaxSize int64
+	documentLinkTTL time.Duration
+	documentLinkKey []byte
 }
 
-func NewService(repo *storage.Repository) *Service {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "secretkey0909"
+func NewService(repo *storage.Repository, mailer mail.Sender, blobs blob.Store, logger *zap.SugaredLogger) (*Service, error) {
+	keys, err := loadKeyRing()
+	if err != nil {
+		return nil, err
 	}
 
-	return &Service{
-		repo:      repo,
-		jwtSecret: jwtSecret,
+	jwtIssuer := os.Getenv("JWT_ISSUER")
+	if jwtIssuer == "" {
+		jwtIssuer = "university-api"
+	}
+
+	// Base URL of the frontend, used to build links in emails
+	appBaseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+	if appBaseURL == "" {
+		appBaseURL = "http://localhost:8080"
 	}
+
+	oidc, err := loadOIDCProvider(appBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := passwordPolicyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	passwords, err := passwordHasherFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	documentLinkKey, err := loadDocumentLinkKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		repo:             repo,
+		mailer:           mailer,
+		logger:           logger,
+		keys:             keys,
+		jwtIssuer:        jwtIssuer,
+		appBaseURL:       appBaseURL,
+		accessTokenTTL:   durationFromEnv("ACCESS_TOKEN_TTL", 15*time.Minute),
+		refreshTokenTTL:  durationFromEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		passwordResetTTL: durationFromEnv("PASSWORD_RESET_TTL", time.Hour),
+		impersonationTTL: durationFromEnv("IMPERSONATION_TTL", 30*time.Minute),
+		invitationTTL:    durationFromEnv("INVITATION_TTL", 7*24*time.Hour),
+
+		emailVerificationTTL:     durationFromEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		requireEmailVerification: os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true",
+
+		throttle: loginThrottleFromEnv(),
+		mfa:      mfaPolicyFromEnv(),
+
+		passwordPolicy: policy,
+		passwords:      passwords,
+
+		oidc: oidc,
+
+		blobs:           blobs,
+		documentMaxSize: int64(intFromEnv("DOCUMENT_MAX_BYTES", 10<<20)),
+		documentLinkTTL: durationFromEnv("DOCUMENT_LINK_TTL", 15*time.Minute),
+		documentLinkKey: documentLinkKey,
+	}, nil
 }
 
 func (s *Service) GetStudentByID(id string) (*model.StudentResponse, error) {
@@ -39,28 +122,65 @@ func (s *Service) CreateStudent(req *model.CreateStudentRequest) (*model.Student
 	return s.repo.CreateStudent(req)
 }
 
+// UpdateStudent changes a student. A group change takes effect on
+// req.GroupEffectiveFrom, which may be backdated but not in the future.
 func (s *Service) UpdateStudent(id string, req *model.UpdateStudentRequest) (*model.StudentResponse, error) {
-	return s.repo.UpdateStudent(id, req)
+	effective := time.Now()
+	if req.GroupEffectiveFrom != nil && *req.GroupEffectiveFrom != "" {
+		date, err := time.Parse(time.DateOnly, *req.GroupEffectiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("%w: group_effective_from must be a date in YYYY-MM-DD format", ErrInvalidDate)
+		}
+		if date.After(effective) {
+			return nil, fmt.Errorf("%w: group_effective_from is in the future", ErrInvalidDate)
+		}
+		effective = date
+	}
+	return s.repo.UpdateStudent(id, req, effective)
 }
 
-func (s *Service) DeleteStudent(id string) error {
-	return s.repo.DeleteStudent(id)
+// ArchiveStudent hides a student from default listings, keeping their records
+func (s *Service) ArchiveStudent(id string) error {
+	return s.repo.ArchiveStudent(id)
 }
 
-func (s *Service) GetAllStudents() ([]model.StudentListResponse, error) {
-	return s.repo.GetAllStudents()
+// RestoreStudent brings an archived student back
+func (s *Service) RestoreStudent(id string) (*model.StudentResponse, error) {
+	return s.repo.RestoreStudent(id)
+}
+
+// PurgeStudent permanently deletes an archived student with their attendance,
+// grades and documents
+func (s *Service) PurgeStudent(id string) (*model.StudentPurgeResult, error) {
+	result, storageKeys, err := s.repo.PurgeStudent(id)
+	if err != nil {
+		return nil, err
+	}
+	// The rows are already gone, so a failed file delete is reported rather
+	// than failing the purge
+	for _, key := range storageKeys {
+		if err := s.blobs.Delete(key); err != nil {
+			s.logger.Errorw("failed to delete file of purged student", "student_id", id, "storage_key", key, "error", err)
+			result.UndeletedFiles = append(result.UndeletedFiles, key)
+		}
+	}
+	return result, nil
 }
 
-func (s *Service) GetStudentsGPA() ([]model.StudentGPAResponse, error) {
-	return s.repo.GetStudentsGPA()
+func (s *Service) GetAllStudents(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.StudentListResponse], error) {
+	return s.repo.GetAllStudents(filter, params)
+}
+
+func (s *Service) GetStudentsGPA(status string) ([]model.StudentGPAResponse, error) {
+	return s.repo.GetStudentsGPA(status)
 }
 
 func (s *Service) GetSubjectStats() ([]model.SubjectStatsResponse, error) {
 	return s.repo.GetSubjectStats()
 }
 
-func (s *Service) GetAllSchedules() ([]model.ScheduleResponse, error) {
-	return s.repo.GetAllSchedules()
+func (s *Service) GetAllSchedules(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.ScheduleResponse], error) {
+	return s.repo.GetAllSchedules(filter, params)
 }
 
 func (s *Service) CreateSchedule(req *model.CreateScheduleRequest) (*model.ScheduleResponse, error) {
@@ -79,10 +199,6 @@ func (s *Service) GetScheduleByID(id string) (*model.ScheduleResponse, error) {
 	return s.repo.GetScheduleByID(id)
 }
 
-func (s *Service) GetGroupSchedule(groupID string) ([]model.ScheduleResponse, error) {
-	return s.repo.GetGroupSchedule(groupID)
-}
-
 func (s *Service) CreateFaculty(req *model.CreateFacultyRequest) (*model.FacultyResponse, error) {
 	return s.repo.CreateFaculty(req)
 }
@@ -91,8 +207,8 @@ func (s *Service) GetFacultyByID(id string) (*model.FacultyResponse, error) {
 	return s.repo.GetFacultyByID(id)
 }
 
-func (s *Service) GetAllFaculties() ([]model.FacultyResponse, error) {
-	return s.repo.GetAllFaculties()
+func (s *Service) GetAllFaculties(params *model.ListParams) (*model.Page[model.FacultyResponse], error) {
+	return s.repo.GetAllFaculties(params)
 }
 
 func (s *Service) CreateGroup(req *model.CreateGroupRequest) (*model.GroupResponse, error) {
@@ -103,8 +219,8 @@ func (s *Service) GetGroupByID(id string) (*model.GroupResponse, error) {
 	return s.repo.GetGroupByID(id)
 }
 
-func (s *Service) GetAllGroups() ([]model.GroupResponse, error) {
-	return s.repo.GetAllGroups()
+func (s *Service) GetAllGroups(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.GroupResponse], error) {
+	return s.repo.GetAllGroups(filter, params)
 }
 
 func (s *Service) CreateSubject(req *model.CreateSubjectRequest) (*model.SubjectResponse, error) {
@@ -115,16 +231,8 @@ func (s *Service) GetSubjectByID(id string) (*model.SubjectResponse, error) {
 	return s.repo.GetSubjectByID(id)
 }
 
-func (s *Service) GetAllSubjects() ([]model.SubjectResponse, error) {
-	return s.repo.GetAllSubjects()
-}
-
-func (s *Service) CreateAttendanceRecord(req *model.CreateAttendanceRequest) (*model.AttendanceRecord, error) {
-	return s.repo.CreateAttendanceRecord(req)
-}
-
-func (s *Service) UpdateAttendanceRecord(id string, req *model.UpdateAttendanceRequest) (*model.AttendanceRecord, error) {
-	return s.repo.UpdateAttendanceRecord(id, req)
+func (s *Service) GetAllSubjects(params *model.ListParams) (*model.Page[model.SubjectResponse], error) {
+	return s.repo.GetAllSubjects(params)
 }
 
 func (s *Service) DeleteAttendanceRecord(id string) error {
@@ -135,20 +243,12 @@ func (s *Service) GetAttendanceByID(id string) (*model.AttendanceRecord, error)
 	return s.repo.GetAttendanceByID(id)
 }
 
-func (s *Service) GetAllAttendanceRecords() ([]model.AttendanceRecord, error) {
-	return s.repo.GetAllAttendanceRecords()
-}
-
-func (s *Service) GetAttendanceRecordsByStudentID(studentID string) ([]model.AttendanceRecord, error) {
-	return s.repo.GetAttendanceRecordsByStudentID(studentID)
-}
-
-func (s *Service) GetAttendanceRecordsBySubjectID(subjectID string) ([]model.AttendanceRecord, error) {
-	return s.repo.GetAttendanceRecordsBySubjectID(subjectID)
+func (s *Service) GetAllAttendanceRecords(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.AttendanceRecord], error) {
+	return s.repo.GetAllAttendanceRecords(filter, params)
 }
 
 // Register creates a new user account
-func (s *Service) Register(req *model.AuthRequest) (*model.User, error) {
+func (s *Service) Register(req *model.RegisterRequest) (*model.User, error) {
 	// Validate email is not empty
 	if req.Email == "" {
 		return nil, errors.New("email is required")
@@ -164,9 +264,9 @@ func (s *Service) Register(req *model.AuthRequest) (*model.User, error) {
 		return nil, errors.New("invalid email format")
 	}
 
-	// Validate password length
-	if len(req.Password) < 6 {
-		return nil, errors.New("password must be at least 6 characters")
+	// Validate password strength
+	if err := s.validatePassword(req.Password, req.Email); err != nil {
+		return nil, err
 	}
 
 	// Check if user already exists
@@ -179,22 +279,34 @@ func (s *Service) Register(req *model.AuthRequest) (*model.User, error) {
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hashPassword(req.Password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, err
 	}
 
-	// Create user
-	user, err := s.repo.CreateUser(req.Email, string(hashedPassword))
+	// Create user, linked to their student or staff record when invited
+	var user *model.User
+	if req.InvitationCode != "" {
+		user, err = s.registerInvited(req.Email, hashedPassword, req.InvitationCode)
+	} else {
+		user, err = s.repo.CreateUser(req.Email, hashedPassword)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// Send email verification link. The account is already created, so a
+	// failed send is logged and the user can ask for the link to be resent.
+	if err := s.sendVerificationEmail(user); err != nil {
+		s.logger.Errorw("failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
 	return user, nil
 }
 
-// Login authenticates user and returns JWT token
-func (s *Service) Login(req *model.AuthRequest) (*model.LoginResponse, error) {
+// Login authenticates user and returns an access token and a refresh token.
+// Failed attempts are tracked per email and per client address.
+func (s *Service) Login(req *model.AuthRequest, client model.ClientInfo) (*model.LoginResponse, error) {
 	// Validate email is not empty
 	if req.Email == "" {
 		return nil, errors.New("email is required")
@@ -205,10 +317,18 @@ func (s *Service) Login(req *model.AuthRequest) (*model.LoginResponse, error) {
 		return nil, errors.New("password is required")
 	}
 
+	// Refuse attempts while the email or client address is locked out
+	if err := s.checkLoginLock(req.Email, client.IP); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.repo.GetUserByEmail(req.Email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
+			if err := s.recordLoginFailure(req.Email, client.IP); err != nil {
+				return nil, err
+			}
 			return nil, errors.New("invalid email or password")
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -219,29 +339,30 @@ func (s *Service) Login(req *model.AuthRequest) (*model.LoginResponse, error) {
 		return nil, errors.New("user account is inactive")
 	}
 
-	// Compare passwords
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	// Compare passwords, upgrading the stored hash if the hash settings changed
+	ok, err := s.checkPassword(user, req.Password)
 	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.recordLoginFailure(req.Email, client.IP); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
-	})
+	// Successful password check resets the account's failure counter
+	if err := s.repo.ClearLoginAttempts(emailThrottleKey(req.Email)); err != nil {
+		return nil, fmt.Errorf("failed to reset login attempts: %w", err)
+	}
 
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign token: %w", err)
+	// Check if email is verified (when required)
+	if s.requireEmailVerification && user.EmailVerifiedAt == nil {
+		return nil, errors.New("email address is not verified")
 	}
 
-	return &model.LoginResponse{
-		Token: tokenString,
-		User:  user,
-	}, nil
+	// Issue tokens, or an mfa_token when a second factor is needed
+	return s.completeLogin(user, client)
 }
 
 // GetCurrentUser retrieves user info by ID
@@ -249,44 +370,52 @@ func (s *Service) GetCurrentUser(userID string) (*model.UserResponse, error) {
 	return s.repo.GetUserByID(userID)
 }
 
-// ValidateToken validates JWT token and returns user ID as string
-func (s *Service) ValidateToken(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
+// GetUserRoles returns the role names assigned to a user
+func (s *Service) GetUserRoles(userID string) ([]string, error) {
+	return s.repo.GetUserRoles(userID)
+}
 
+// ValidateToken validates a JWT access token and returns its claims.
+// Tokens whose ID has been revoked via Logout are rejected.
+func (s *Service) ValidateToken(tokenString string) (*AccessClaims, error) {
+	claims, err := s.keys.parse(tokenString, s.jwtIssuer)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse token: %w", err)
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.MapClaims)
-	if !ok || !token.Valid {
-		return "", errors.New("invalid token claims")
+	if claims.UserID == 0 {
+		return nil, errors.New("user_id not found in token")
 	}
 
-	// Handle user_id as either int or float64 (JWT encodes numbers as float64)
-	userIDValue, exists := (*claims)["user_id"]
-	if !exists {
-		return "", errors.New("user_id not found in token")
+	// Check the denylist for logged out tokens
+	if claims.ID != "" {
+		revoked, err := s.repo.IsTokenRevoked(claims.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token status: %w", err)
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
 	}
 
-	var userID int
-	switch v := userIDValue.(type) {
-	case float64:
-		userID = int(v)
-	case int:
-		userID = v
-	case int64:
-		userID = int(v)
-	default:
-		return "", errors.New("invalid user_id type in token")
-	}
+	return claims, nil
+}
 
-	return fmt.Sprintf("%d", userID), nil
+// JWKS returns the public keys used to verify access tokens
+func (s *Service) JWKS() *model.JWKSResponse {
+	return s.keys.jwks()
+}
+
+// IsUserActive reports whether the user account exists and is active
+func (s *Service) IsUserActive(userID int) (bool, error) {
+	user, err := s.repo.GetUserAccountByID(userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.IsActive, nil
 }
 
 // isValidEmail validates email format using regex
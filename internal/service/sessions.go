@@ -0,0 +1,43 @@
+package service
+
+import (
+	"unicode/utf8"
+	"university/internal/model"
+)
+
+// ListSessions returns the user's active sessions, marking the one the
+// request was made with
+func (s *Service) ListSessions(userID, currentSessionID int) ([]model.Session, error) {
+	sessions, err := s.repo.ListSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs the user out of one of their sessions. Its access
+// tokens stop working immediately and its refresh token is revoked.
+func (s *Service) RevokeSession(userID, sessionID int) error {
+	return s.repo.RevokeSession(userID, sessionID)
+}
+
+// IsSessionActive reports whether the session an access token belongs to is still active
+func (s *Service) IsSessionActive(sessionID int) (bool, error) {
+	return s.repo.IsSessionActive(sessionID)
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"university/internal/model"
+)
+
+// ErrNotAssigned is returned when a teacher writes attendance or grades outside their teaching assignments
+var ErrNotAssigned = errors.New("you can only record attendance and grades for groups and subjects you teach in your faculty")
+
+// ErrInvalidGrade is returned when a grade request is missing fields or out of range
+var ErrInvalidGrade = errors.New("invalid grade")
+
+// authorizeTeaching checks that the actor may record attendance or grades for
+// the student in the subject. Admins may write anything and API keys need the
+// attendance:write:all scope; teachers need a teaching assignment for the
+// student's group and the subject within their own faculty.
+func (s *Service) authorizeTeaching(actor model.Actor, studentID, subjectID int) error {
+	if actor.APIKeyID != 0 {
+		if slices.Contains(actor.Scopes, model.ScopeAttendanceWriteAll) {
+			return nil
+		}
+		return ErrNotAssigned
+	}
+	if slices.Contains(actor.Roles, model.RoleAdmin) {
+		return nil
+	}
+	if !slices.Contains(actor.Roles, model.RoleTeacher) {
+		return ErrNotAssigned
+	}
+
+	teaches, err := s.repo.TeachesStudentSubject(actor.UserID, studentID, subjectID)
+	if err != nil {
+		return fmt.Errorf("failed to check teaching assignments: %w", err)
+	}
+	if !teaches {
+		return ErrNotAssigned
+	}
+	return nil
+}
+
+func (s *Service) CreateAttendanceRecord(actor model.Actor, req *model.CreateAttendanceRequest) (*model.AttendanceRecord, error) {
+	if err := s.authorizeTeaching(actor, req.StudentID, req.SubjectID); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateAttendanceRecord(req)
+}
+
+// UpdateAttendanceRecord checks the actor may write both the current record and,
+// when it is moved to another student or subject, the new one
+func (s *Service) UpdateAttendanceRecord(actor model.Actor, id string, req *model.UpdateAttendanceRequest) (*model.AttendanceRecord, error) {
+	existing, err := s.repo.GetAttendanceByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTeaching(actor, existing.StudentID, existing.SubjectID); err != nil {
+		return nil, err
+	}
+
+	studentID, subjectID := existing.StudentID, existing.SubjectID
+	if req.StudentID != nil {
+		studentID = *req.StudentID
+	}
+	if req.SubjectID != nil {
+		subjectID = *req.SubjectID
+	}
+	if studentID != existing.StudentID || subjectID != existing.SubjectID {
+		if err := s.authorizeTeaching(actor, studentID, subjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.UpdateAttendanceRecord(id, req)
+}
+
+// CreateGrade posts a grade for a student the actor teaches
+func (s *Service) CreateGrade(actor model.Actor, req *model.CreateGradeRequest) (*model.GradeResponse, error) {
+	if req.StudentID == 0 || req.SubjectID == 0 {
+		return nil, fmt.Errorf("%w: student_id and subject_id are required", ErrInvalidGrade)
+	}
+	if err := validateGrade(req.Grade); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTeaching(actor, req.StudentID, req.SubjectID); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateGrade(req)
+}
+
+// UpdateGrade corrects a grade the actor is allowed to write
+func (s *Service) UpdateGrade(actor model.Actor, id int, req *model.UpdateGradeRequest) (*model.GradeResponse, error) {
+	if err := validateGrade(req.Grade); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetGradeByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTeaching(actor, existing.StudentID, existing.SubjectID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateGrade(id, req.Grade)
+}
+
+func (s *Service) DeleteGrade(id int) error {
+	return s.repo.DeleteGrade(id)
+}
+
+// CreateTeachingAssignment assigns a subject and group to a staff member of the group's faculty
+func (s *Service) CreateTeachingAssignment(req *model.CreateTeachingAssignmentRequest) (*model.TeachingAssignment, error) {
+	if req.StaffID == 0 || req.GroupID == 0 || req.SubjectID == 0 {
+		return nil, errors.New("staff_id, group_id and subject_id are required")
+	}
+	return s.repo.CreateTeachingAssignment(req)
+}
+
+func (s *Service) ListTeachingAssignments(staffID int) ([]model.TeachingAssignment, error) {
+	return s.repo.ListTeachingAssignments(staffID)
+}
+
+func (s *Service) DeleteTeachingAssignment(id int) error {
+	return s.repo.DeleteTeachingAssignment(id)
+}
+
+// validateGrade checks a grade fits the grades column (NUMERIC(4,2))
+func validateGrade(grade float64) error {
+	if grade < 0 || grade >= 100 {
+		return fmt.Errorf("%w: grade must be between 0 and 99.99", ErrInvalidGrade)
+	}
+	return nil
+}
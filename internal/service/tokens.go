@@ -0,0 +1,232 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"university/internal/model"
+	"university/internal/storage"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// Errors returned by Refresh
+var (
+	// ErrRefreshTokenRequired is returned when no refresh token is supplied
+	ErrRefreshTokenRequired = errors.New("refresh token is required")
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown or revoked
+	ErrInvalidRefreshToken = errors.New("invalid or revoked refresh token")
+	// ErrRefreshTokenExpired is returned when a refresh token is past its expiry
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	// ErrRefreshTokenReused is returned when a rotated refresh token is
+	// presented again, which revokes its whole family
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, sign in again")
+	// ErrAccountInactive is returned when the token owner has been deactivated
+	ErrAccountInactive = errors.New("user account is inactive")
+)
+
+// AccessClaims are the claims carried by access tokens. Impersonation tokens
+// carry the impersonated user in UserID and the real admin in ActorID.
+type AccessClaims struct {
+	UserID          int    `json:"user_id"`
+	Email           string `json:"email"`
+	SessionID       int    `json:"sid,omitempty"`
+	ActorID         int    `json:"actor_id,omitempty"`
+	ImpersonationID int    `json:"imp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Logout revokes the access token described by claims and ends its session.
+// When a refresh token is supplied, the refresh token family it belongs to is
+// revoked as well.
+func (s *Service) Logout(claims *AccessClaims, req *model.LogoutRequest) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.repo.RevokeToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+	}
+
+	if claims.ImpersonationID != 0 {
+		if err := s.repo.EndImpersonation(claims.ImpersonationID); err != nil {
+			return fmt.Errorf("failed to end impersonation: %w", err)
+		}
+	}
+
+	if claims.SessionID != 0 {
+		err := s.repo.RevokeSession(claims.UserID, claims.SessionID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to end session: %w", err)
+		}
+	}
+
+	if req.RefreshToken != "" {
+		err := s.repo.RevokeRefreshTokenFamilyByToken(claims.UserID, hashToken(req.RefreshToken))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a rotated refresh token.
+// Reusing a refresh token that was already exchanged revokes its whole family.
+func (s *Service) Refresh(req *model.RefreshRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, ErrRefreshTokenRequired
+	}
+
+	newToken, newHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	userID, familyID, err := s.repo.RotateRefreshToken(hashToken(req.RefreshToken), newHash, s.refreshTokenTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrInvalidRefreshToken
+		case errors.Is(err, storage.ErrRefreshTokenExpired):
+			return nil, ErrRefreshTokenExpired
+		case errors.Is(err, storage.ErrRefreshTokenReused):
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	user, err := s.repo.GetUserAccountByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Deactivated users lose their sessions on the next refresh
+	if !user.IsActive {
+		if err := s.repo.RevokeRefreshTokenFamily(familyID); err != nil {
+			return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil, ErrAccountInactive
+	}
+
+	sessionID, err := s.repo.TouchSessionByFamily(familyID, truncate(client.UserAgent, 512), client.IP)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	accessToken, err := s.signAccessToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: newToken,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+		User:         user,
+	}, nil
+}
+
+// issueTokens starts a new session with its own refresh token family for the
+// user and signs an access token bound to it
+func (s *Service) issueTokens(user *model.User, client model.ClientInfo) (*model.LoginResponse, error) {
+	refreshToken, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRefreshToken(user.ID, refreshHash, familyID, s.refreshTokenTTL); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	sessionID, err := s.repo.CreateSession(user.ID, familyID, truncate(client.UserAgent, 512), client.IP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+
+	accessToken, err := s.signAccessToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+		User:         user,
+	}, nil
+}
+
+// signAccessToken generates a short-lived JWT access token for the user's session
+func (s *Service) signAccessToken(user *model.User, sessionID int) (string, error) {
+	tokenID, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return s.keys.sign(AccessClaims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    s.jwtIssuer,
+			Subject:   strconv.Itoa(user.ID),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+}
+
+// newOpaqueToken returns a random URL-safe token and the hash under which it is stored
+func newOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 of an opaque token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// durationFromEnv parses a duration such as "15m" from an environment variable,
+// falling back to def when it is unset or invalid
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
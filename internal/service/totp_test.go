@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed "12345678901234567890" of RFC 6238 appendix B
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// RFC 6238 lists 8-digit codes; 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := totpCode(rfc6238Secret, tt.unix/totpPeriod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTPCodeSecret(t *testing.T) {
+	lower, err := totpCode("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upper, err := totpCode(rfc6238Secret, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lower != upper {
+		t.Errorf("lower-case secret gave %s, want %s", lower, upper)
+	}
+
+	if _, err := totpCode("not base32!", 1); err == nil {
+		t.Error("totpCode accepted an invalid secret")
+	}
+}
+
+func TestVerifyTOTPSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+
+	for offset := int64(-2); offset <= 2; offset++ {
+		code, err := totpCode(rfc6238Secret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		step, ok := verifyTOTP(rfc6238Secret, code, now)
+		inWindow := offset >= -totpSkew && offset <= totpSkew
+		if ok != inWindow {
+			t.Errorf("code of step %+d: ok = %v, want %v", offset, ok, inWindow)
+		}
+		if ok && step != current+offset {
+			t.Errorf("code of step %+d: matched step %d, want %d", offset, step, current+offset)
+		}
+	}
+}
+
+func TestVerifyTOTPReplayStep(t *testing.T) {
+	// The replay check stores the matched step and only accepts later ones, so
+	// a code reused within its window must keep reporting the same step
+	issued := time.Unix(1234567890, 0)
+	code, err := totpCode(rfc6238Secret, issued.Unix()/totpPeriod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, ok := verifyTOTP(rfc6238Secret, code, issued)
+	if !ok {
+		t.Fatal("fresh code rejected")
+	}
+	again, ok := verifyTOTP(rfc6238Secret, code, issued.Add(totpPeriod*time.Second))
+	if !ok {
+		t.Fatal("code rejected one step later")
+	}
+	if again != first {
+		t.Errorf("replayed code matched step %d, want %d", again, first)
+	}
+
+	next, err := totpCode(rfc6238Secret, first+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step, ok := verifyTOTP(rfc6238Secret, next, issued); !ok || step <= first {
+		t.Errorf("next code matched step %d (ok %v), want a step after %d", step, ok, first)
+	}
+}
+
+func TestVerifyTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"287082", true},
+		{"287 082", true},
+		{"287083", false},
+		{"28708", false},
+		{"2870820", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := verifyTOTP(rfc6238Secret, tt.code, now); ok != tt.want {
+			t.Errorf("verifyTOTP(%q) = %v, want %v", tt.code, ok, tt.want)
+		}
+	}
+
+	if _, ok := verifyTOTP("not base32!", "287082", now); ok {
+		t.Error("verifyTOTP accepted a code for an invalid secret")
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"abcde-12345", "abcde12345"},
+		{"ABCDE-12345", "abcde12345"},
+		{" abcde 12345 ", "abcde12345"},
+		{"ab-cd-e1-23-45", "abcde12345"},
+		{"abcde12345", "abcde12345"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeRecoveryCode(tt.code); got != tt.want {
+			t.Errorf("normalizeRecoveryCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"university/internal/mail"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidVerificationToken is returned when an email verification token is unknown, used or expired
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+// VerifyEmail marks the email of the token owner as verified
+func (s *Service) VerifyEmail(token string) error {
+	if token == "" {
+		return errors.New("token is required")
+	}
+
+	if _, err := s.repo.VerifyEmail(hashToken(token)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return nil
+}
+
+// ResendVerification sends a fresh verification link to an unverified account.
+// It does not reveal whether an account exists for the given email.
+func (s *Service) ResendVerification(req *model.ResendVerificationRequest) error {
+	if req.Email == "" {
+		return ErrEmailRequired
+	}
+	if !isValidEmail(req.Email) {
+		return ErrInvalidEmailFormat
+	}
+
+	user, err := s.repo.GetUserByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.IsActive || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+// sendVerificationEmail creates a verification token and emails the link to the user
+func (s *Service) sendVerificationEmail(user *model.User) error {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateEmailVerificationToken(user.ID, tokenHash, s.emailVerificationTTL); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.appBaseURL, url.QueryEscape(token))
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"Welcome! Please confirm that this is your email address by opening the link below.\n\n%s\n\n"+
+				"The link expires in %s. If you did not create an account, you can ignore this email.\n",
+			link, s.emailVerificationTTL,
+		),
+	}
+	return s.mailer.Send(msg)
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"time"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const apiKeyColumns = `id, name, key_prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at`
+
+// CreateAPIKey stores a new API key by hash. A zero ttl creates a key that never expires.
+func (r *Repository) CreateAPIKey(name, prefix, keyHash string, scopes []string, createdBy int, ttl time.Duration) (*model.APIKey, error) {
+	query := `
+	INSERT INTO api_keys (name, key_prefix, key_hash, scopes, created_by, expires_at)
+	VALUES ($1, $2, $3, $4, $5,
+	        CASE WHEN $6::float8 > 0 THEN CURRENT_TIMESTAMP + make_interval(secs => $6) END)
+	RETURNING ` + apiKeyColumns
+
+	row := r.pool.QueryRow(context.Background(), query, name, prefix, keyHash, scopes, createdBy, ttl.Seconds())
+	return scanAPIKey(row)
+}
+
+// ListAPIKeys returns all API keys, newest first
+func (r *Repository) ListAPIKeys() ([]model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id DESC`
+
+	rows, err := r.pool.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes an API key. It returns pgx.ErrNoRows when the key does
+// not exist or is already revoked.
+func (r *Repository) RevokeAPIKey(id int) error {
+	query := `
+	UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND revoked_at IS NULL
+	RETURNING id
+	`
+	return r.pool.QueryRow(context.Background(), query, id).Scan(&id)
+}
+
+// GetActiveAPIKeyByHash retrieves an unrevoked, unexpired API key by hash and
+// records its use. last_used_at is only written once a minute to spare the table.
+func (r *Repository) GetActiveAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	query := `
+	SELECT ` + apiKeyColumns + ` FROM api_keys
+	WHERE key_hash = $1 AND revoked_at IS NULL
+	  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`
+	key, err := scanAPIKey(r.pool.QueryRow(context.Background(), query, keyHash))
+	if err != nil {
+		return nil, err
+	}
+
+	touchQuery := `
+	UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`
+	if _, err := r.pool.Exec(context.Background(), touchQuery, key.ID); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var key model.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.Scopes,
+		&key.CreatedBy,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
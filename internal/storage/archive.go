@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNotArchived is returned when purging a student that is not archived
+var ErrNotArchived = errors.New("student is not archived")
+
+// ArchiveStudent hides a student from default listings while keeping their
+// attendance and grades. Archiving an archived student keeps the original
+// time. It returns pgx.ErrNoRows when the student does not exist.
+func (r *Repository) ArchiveStudent(id string) error {
+	query := `
+	UPDATE students SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+	WHERE id = $1
+	RETURNING id
+	`
+	var studentID int
+	return r.pool.QueryRow(context.Background(), query, id).Scan(&studentID)
+}
+
+// RestoreStudent brings an archived student back. It returns pgx.ErrNoRows
+// when the student does not exist.
+func (r *Repository) RestoreStudent(id string) (*model.StudentResponse, error) {
+	query := `UPDATE students SET archived_at = NULL WHERE id = $1 RETURNING id`
+	var studentID int
+	if err := r.pool.QueryRow(context.Background(), query, id).Scan(&studentID); err != nil {
+		return nil, err
+	}
+	return r.GetStudentByID(id)
+}
+
+// PurgeStudent permanently deletes an archived student together with their
+// attendance, grades and documents, in one transaction, and returns the
+// storage keys of the documents' files. It returns pgx.ErrNoRows when the
+// student does not exist and ErrNotArchived when they were not archived first.
+func (r *Repository) PurgeStudent(id string) (*model.StudentPurgeResult, []string, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result := &model.StudentPurgeResult{}
+	archived := false
+	lockQuery := `SELECT id, archived_at IS NOT NULL FROM students WHERE id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, lockQuery, id).Scan(&result.StudentID, &archived); err != nil {
+		return nil, nil, err
+	}
+	if !archived {
+		return nil, nil, ErrNotArchived
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM attendance WHERE student_id = $1`, result.StudentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.AttendanceDeleted = int(tag.RowsAffected())
+
+	tag, err = tx.Exec(ctx, `DELETE FROM grades WHERE student_id = $1`, result.StudentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.GradesDeleted = int(tag.RowsAffected())
+
+	rows, err := tx.Query(ctx, `DELETE FROM documents WHERE student_id = $1 RETURNING storage_key`, result.StudentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	storageKeys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, nil, err
+	}
+	result.DocumentsDeleted = len(storageKeys)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM students WHERE id = $1`, result.StudentID); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return result, storageKeys, nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const documentColumns = `id, student_id, staff_id, kind, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by, created_at`
+
+// CreateDocument stores the metadata of an uploaded document. It returns
+// pgx.ErrNoRows when the student or staff record does not exist.
+func (r *Repository) CreateDocument(doc *model.Document) (*model.Document, error) {
+	table, recordID := recordTarget(deref(doc.StudentID), deref(doc.StaffID))
+
+	query := `
+	INSERT INTO documents (student_id, staff_id, kind, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by)
+	SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
+	WHERE EXISTS (SELECT 1 FROM ` + table + ` WHERE id = $10)
+	RETURNING ` + documentColumns
+
+	return scanDocument(r.pool.QueryRow(context.Background(), query,
+		doc.StudentID,
+		doc.StaffID,
+		doc.Kind,
+		doc.FileName,
+		doc.ContentType,
+		doc.Size,
+		doc.SHA256,
+		doc.StorageKey,
+		doc.UploadedBy,
+		recordID,
+	))
+}
+
+// ListDocuments returns the documents of a student or staff record, newest first
+func (r *Repository) ListDocuments(studentID, staffID int) ([]model.Document, error) {
+	query := `
+	SELECT ` + documentColumns + ` FROM documents
+	WHERE student_id = $1 OR staff_id = $2
+	ORDER BY id DESC
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, nullableID(studentID), nullableID(staffID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []model.Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, *doc)
+	}
+	return documents, rows.Err()
+}
+
+// GetDocument returns a document's metadata
+func (r *Repository) GetDocument(id int) (*model.Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM documents WHERE id = $1`
+	return scanDocument(r.pool.QueryRow(context.Background(), query, id))
+}
+
+// DeleteDocument removes a document's metadata and returns it, so the caller
+// can remove the file. It returns pgx.ErrNoRows when the document does not exist.
+func (r *Repository) DeleteDocument(id int) (*model.Document, error) {
+	query := `DELETE FROM documents WHERE id = $1 RETURNING ` + documentColumns
+	return scanDocument(r.pool.QueryRow(context.Background(), query, id))
+}
+
+func scanDocument(row pgx.Row) (*model.Document, error) {
+	var doc model.Document
+	err := row.Scan(
+		&doc.ID,
+		&doc.StudentID,
+		&doc.StaffID,
+		&doc.Kind,
+		&doc.FileName,
+		&doc.ContentType,
+		&doc.Size,
+		&doc.SHA256,
+		&doc.StorageKey,
+		&doc.UploadedBy,
+		&doc.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
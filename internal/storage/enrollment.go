@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidTransition is returned when a student cannot move from their
+// current enrollment status to the requested one
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// studentStatus maps a status filter to the status to match: enrolled when
+// empty and none for "all"
+func studentStatus(filter string) string {
+	switch filter {
+	case "":
+		return model.StudentEnrolled
+	case "all":
+		return ""
+	default:
+		return filter
+	}
+}
+
+// ChangeStudentStatus moves a student to status and records the change with
+// its reason and the acting user, in one transaction. allowedFrom lists the statuses
+// the student may be in for the move. It returns pgx.ErrNoRows when the
+// student does not exist and ErrInvalidTransition otherwise.
+func (r *Repository) ChangeStudentStatus(id, status string, allowedFrom []string, reason string, actorID int) (*model.StudentStatusChange, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var studentID int
+	var current string
+	lockQuery := `SELECT id, status FROM students WHERE id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, lockQuery, id).Scan(&studentID, &current); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(allowedFrom, current) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, current, status)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE students SET status = $2 WHERE id = $1`, studentID, status); err != nil {
+		return nil, err
+	}
+
+	query := `
+	INSERT INTO student_status_changes (student_id, from_status, to_status, reason, changed_by)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING ` + statusChangeColumns
+	change, err := scanStatusChange(tx.QueryRow(ctx, query,
+		studentID,
+		current,
+		status,
+		reason,
+		actorID,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// GetStudentStatusChanges returns a student's status changes, oldest first.
+// It returns pgx.ErrNoRows when the student does not exist.
+func (r *Repository) GetStudentStatusChanges(id string) ([]model.StudentStatusChange, error) {
+	ctx := context.Background()
+
+	var studentID int
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM students WHERE id = $1`, id).Scan(&studentID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + statusChangeColumns + ` FROM student_status_changes WHERE student_id = $1 ORDER BY id`
+	rows, err := r.pool.Query(ctx, query, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.StudentStatusChange{}
+	for rows.Next() {
+		change, err := scanStatusChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *change)
+	}
+	return changes, rows.Err()
+}
+
+const statusChangeColumns = `id, student_id, from_status, to_status, reason, changed_by, created_at`
+
+func scanStatusChange(row pgx.Row) (*model.StudentStatusChange, error) {
+	var change model.StudentStatusChange
+	err := row.Scan(
+		&change.ID,
+		&change.StudentID,
+		&change.FromStatus,
+		&change.ToStatus,
+		&change.Reason,
+		&change.ChangedBy,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"university/internal/model"
+)
+
+// StreamStudents passes every student matching filter to fn in sort order,
+// one row at a time, so exports never hold the whole result in memory. It
+// stops at the first error fn returns.
+func (r *Repository) StreamStudents(filter *model.ListFilter, sort string, fn func(*model.StudentExportRow) error) error {
+	q, order, err := studentListQuery(filter, sort)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	SELECT s.id, COALESCE(s.first_name, ''), COALESCE(s.last_name, ''),
+	       COALESCE(s.gender, ''), COALESCE(to_char(s.birth_date, 'YYYY-MM-DD'), ''),
+	       COALESCE(g.name, ''), COALESCE(f.name, ''), COALESCE(u.email, ''), s.status
+	FROM ` + studentListFrom + q.whereClause() + order
+
+	rows, err := r.pool.Query(context.Background(), query, q.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var student model.StudentExportRow
+	for rows.Next() {
+		if err := rows.Scan(
+			&student.ID,
+			&student.FirstName,
+			&student.LastName,
+			&student.Gender,
+			&student.BirthDate,
+			&student.GroupName,
+			&student.FacultyName,
+			&student.Email,
+			&student.Status,
+		); err != nil {
+			return err
+		}
+		if err := fn(&student); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
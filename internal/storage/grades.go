@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateGrade records a grade for a student in a subject
+func (r *Repository) CreateGrade(req *model.CreateGradeRequest) (*model.GradeResponse, error) {
+	query := `
+	WITH gr AS (
+		INSERT INTO grades (student_id, subject_id, grade)
+		VALUES ($1, $2, $3)
+		RETURNING id, student_id, subject_id, grade, graded_at
+	)
+	SELECT gr.id, gr.student_id, gr.subject_id, sub.name, gr.grade::float8, gr.graded_at
+	FROM gr
+	JOIN subjects sub ON gr.subject_id = sub.id
+	`
+
+	row := r.pool.QueryRow(context.Background(), query, req.StudentID, req.SubjectID, req.Grade)
+	return scanGrade(row)
+}
+
+// GetGradeByID retrieves a grade
+func (r *Repository) GetGradeByID(id int) (*model.GradeResponse, error) {
+	query := `
+	SELECT gr.id, gr.student_id, gr.subject_id, sub.name, gr.grade::float8, gr.graded_at
+	FROM grades gr
+	JOIN subjects sub ON gr.subject_id = sub.id
+	WHERE gr.id = $1
+	`
+
+	return scanGrade(r.pool.QueryRow(context.Background(), query, id))
+}
+
+// UpdateGrade corrects the value of a grade
+func (r *Repository) UpdateGrade(id int, grade float64) (*model.GradeResponse, error) {
+	query := `
+	WITH gr AS (
+		UPDATE grades SET grade = $2, graded_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING id, student_id, subject_id, grade, graded_at
+	)
+	SELECT gr.id, gr.student_id, gr.subject_id, sub.name, gr.grade::float8, gr.graded_at
+	FROM gr
+	JOIN subjects sub ON gr.subject_id = sub.id
+	`
+
+	return scanGrade(r.pool.QueryRow(context.Background(), query, id, grade))
+}
+
+// DeleteGrade removes a grade.
+// It returns pgx.ErrNoRows when no such grade exists.
+func (r *Repository) DeleteGrade(id int) error {
+	var deleted int
+	query := `DELETE FROM grades WHERE id = $1 RETURNING id`
+	return r.pool.QueryRow(context.Background(), query, id).Scan(&deleted)
+}
+
+func scanGrade(row pgx.Row) (*model.GradeResponse, error) {
+	var grade model.GradeResponse
+	err := row.Scan(
+		&grade.ID,
+		&grade.StudentID,
+		&grade.SubjectID,
+		&grade.Subject,
+		&grade.Grade,
+		&grade.GradedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &grade, nil
+}
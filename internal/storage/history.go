@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidEffectiveDate is returned when a group change is dated before the
+// student's current group began
+var ErrInvalidEffectiveDate = errors.New("group change is dated before the current group began")
+
+const groupPeriodColumns = `h.id, h.student_id, h.group_id, COALESCE(g.name, ''),
+	COALESCE(to_char(h.valid_from, 'YYYY-MM-DD'), ''), COALESCE(to_char(h.valid_to, 'YYYY-MM-DD'), '')`
+
+// recordGroupChange closes the student's current history period on effective
+// and opens one in groupID. A change on the day the current period began
+// replaces that period, so same-day corrections leave no empty spans.
+func recordGroupChange(ctx context.Context, tx pgx.Tx, studentID int, groupID *int, effective time.Time) error {
+	effective = time.Date(effective.Year(), effective.Month(), effective.Day(), 0, 0, 0, 0, time.UTC)
+
+	var openID int
+	var openFrom *time.Time
+	openQuery := `
+	SELECT id, valid_from FROM student_group_history
+	WHERE student_id = $1 AND valid_to IS NULL
+	FOR UPDATE
+	`
+	err := tx.QueryRow(ctx, openQuery, studentID).Scan(&openID, &openFrom)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return err
+	case openFrom != nil && openFrom.After(effective):
+		return ErrInvalidEffectiveDate
+	case openFrom != nil && openFrom.Equal(effective):
+		if _, err := tx.Exec(ctx, `DELETE FROM student_group_history WHERE id = $1`, openID); err != nil {
+			return err
+		}
+	default:
+		if _, err := tx.Exec(ctx, `UPDATE student_group_history SET valid_to = $2 WHERE id = $1`, openID, effective); err != nil {
+			return err
+		}
+	}
+
+	insertQuery := `
+	INSERT INTO student_group_history (student_id, group_id, valid_from)
+	VALUES ($1, $2, $3)
+	`
+	_, err = tx.Exec(ctx, insertQuery, studentID, groupID, effective)
+	return err
+}
+
+// GetStudentGroupHistory returns the groups a student has been in, oldest
+// first. It returns pgx.ErrNoRows when the student does not exist.
+func (r *Repository) GetStudentGroupHistory(studentID string) ([]model.StudentGroupPeriod, error) {
+	ctx := context.Background()
+
+	var id int
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM students WHERE id = $1`, studentID).Scan(&id); err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT ` + groupPeriodColumns + `
+	FROM student_group_history h
+	LEFT JOIN groups g ON g.id = h.group_id
+	WHERE h.student_id = $1
+	ORDER BY h.valid_from NULLS FIRST, h.id
+	`
+	rows, err := r.pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []model.StudentGroupPeriod{}
+	for rows.Next() {
+		period, err := scanGroupPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, *period)
+	}
+	return periods, rows.Err()
+}
+
+// GetStudentGroupOnDate returns the history period covering date, YYYY-MM-DD.
+// It returns pgx.ErrNoRows when the student does not exist or has no group
+// recorded on that date.
+func (r *Repository) GetStudentGroupOnDate(studentID, date string) (*model.StudentGroupPeriod, error) {
+	query := `
+	SELECT ` + groupPeriodColumns + `
+	FROM student_group_history h
+	LEFT JOIN groups g ON g.id = h.group_id
+	WHERE h.student_id = $1
+	  AND (h.valid_from IS NULL OR h.valid_from <= $2::date)
+	  AND (h.valid_to IS NULL OR h.valid_to > $2::date)
+	`
+	return scanGroupPeriod(r.pool.QueryRow(context.Background(), query, studentID, date))
+}
+
+func scanGroupPeriod(row pgx.Row) (*model.StudentGroupPeriod, error) {
+	var period model.StudentGroupPeriod
+	err := row.Scan(
+		&period.ID,
+		&period.StudentID,
+		&period.GroupID,
+		&period.GroupName,
+		&period.ValidFrom,
+		&period.ValidTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"time"
+	"university/internal/model"
+)
+
+// StartImpersonation records an admin starting to act as another user
+func (r *Repository) StartImpersonation(actorID, userID int, reason string, ttl time.Duration) (int, error) {
+	query := `
+	INSERT INTO impersonations (actor_id, user_id, reason, expires_at)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+	RETURNING id
+	`
+
+	var id int
+	err := r.pool.QueryRow(context.Background(), query, actorID, userID, reason, ttl.Seconds()).Scan(&id)
+	return id, err
+}
+
+// IsImpersonationActive reports whether an impersonation has neither ended nor
+// expired and its admin is still an active admin
+func (r *Repository) IsImpersonationActive(impersonationID int) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1
+		FROM impersonations i
+		JOIN users a ON a.id = i.actor_id
+		WHERE i.id = $1 AND i.ended_at IS NULL AND i.expires_at > CURRENT_TIMESTAMP
+		  AND a.is_active
+		  AND EXISTS (
+			SELECT 1 FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+			WHERE ur.user_id = a.id AND r.name = 'ADMIN'
+		  )
+	)
+	`
+
+	var active bool
+	err := r.pool.QueryRow(context.Background(), query, impersonationID).Scan(&active)
+	return active, err
+}
+
+// EndImpersonation marks an impersonation as ended
+func (r *Repository) EndImpersonation(impersonationID int) error {
+	query := `UPDATE impersonations SET ended_at = CURRENT_TIMESTAMP WHERE id = $1 AND ended_at IS NULL`
+	_, err := r.pool.Exec(context.Background(), query, impersonationID)
+	return err
+}
+
+// RecordImpersonatedRequest adds a request made with an impersonation token to the audit trail
+func (r *Repository) RecordImpersonatedRequest(impersonationID int, method, path, ipAddress string) (int, error) {
+	query := `
+	INSERT INTO impersonation_requests (impersonation_id, method, path, ip_address)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id
+	`
+
+	var id int
+	err := r.pool.QueryRow(context.Background(), query, impersonationID, method, path, ipAddress).Scan(&id)
+	return id, err
+}
+
+// SetImpersonatedRequestStatus stores the response status of an audited request
+func (r *Repository) SetImpersonatedRequestStatus(requestID, status int) error {
+	query := `UPDATE impersonation_requests SET status = $2 WHERE id = $1`
+	_, err := r.pool.Exec(context.Background(), query, requestID, status)
+	return err
+}
+
+// ListImpersonations returns impersonations, newest first, optionally only
+// those of one impersonated user
+func (r *Repository) ListImpersonations(userID int) ([]model.Impersonation, error) {
+	query := `
+	SELECT i.id, i.actor_id, a.email, i.user_id, u.email, i.reason,
+	       i.started_at, i.expires_at, i.ended_at,
+	       (SELECT COUNT(*) FROM impersonation_requests ir WHERE ir.impersonation_id = i.id)
+	FROM impersonations i
+	JOIN users a ON a.id = i.actor_id
+	JOIN users u ON u.id = i.user_id
+	WHERE $1 = 0 OR i.user_id = $1
+	ORDER BY i.id DESC
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	impersonations := []model.Impersonation{}
+	for rows.Next() {
+		var imp model.Impersonation
+		err := rows.Scan(
+			&imp.ID,
+			&imp.ActorID,
+			&imp.ActorEmail,
+			&imp.UserID,
+			&imp.UserEmail,
+			&imp.Reason,
+			&imp.StartedAt,
+			&imp.ExpiresAt,
+			&imp.EndedAt,
+			&imp.RequestCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		impersonations = append(impersonations, imp)
+	}
+
+	return impersonations, rows.Err()
+}
+
+// ListImpersonatedRequests returns the audited requests of an impersonation in order
+func (r *Repository) ListImpersonatedRequests(impersonationID int) ([]model.ImpersonatedRequest, error) {
+	query := `
+	SELECT id, method, path, status, COALESCE(ip_address, ''), created_at
+	FROM impersonation_requests
+	WHERE impersonation_id = $1
+	ORDER BY id
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, impersonationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []model.ImpersonatedRequest{}
+	for rows.Next() {
+		var req model.ImpersonatedRequest
+		err := rows.Scan(
+			&req.ID,
+			&req.Method,
+			&req.Path,
+			&req.Status,
+			&req.IPAddress,
+			&req.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"university/internal/model"
+)
+
+// ExistingEmails returns which of the emails already belong to a user,
+// compared case-insensitively and keyed in lower case
+func (r *Repository) ExistingEmails(emails []string) (map[string]bool, error) {
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	rows, err := r.pool.Query(context.Background(), `SELECT lower(email) FROM users WHERE lower(email) = ANY($1)`, lowered)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		existing[email] = true
+	}
+	return existing, rows.Err()
+}
+
+// ImportStudents creates the students in one transaction, together with a
+// STUDENT account for each row that has an email. Accounts have no password;
+// their owners set one through a password reset link. Either every row is
+// written or none is. It fills in StudentID and UserID of the matching report
+// rows.
+func (r *Repository) ImportStudents(students []model.ImportStudent, report []model.StudentImportRow) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	studentQuery := `
+	INSERT INTO students (first_name, last_name, gender, birth_date, group_id, user_id)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')::date, $5, $6)
+	RETURNING id
+	`
+	now := time.Now()
+	userQuery := `INSERT INTO users (email, password_hash) VALUES ($1, '') RETURNING id`
+	roleQuery := `
+	INSERT INTO user_roles (user_id, role_id)
+	SELECT $1, id FROM roles WHERE name = $2
+	`
+
+	for i, student := range students {
+		var userID *int
+		if student.Email != "" {
+			var id int
+			if err := tx.QueryRow(ctx, userQuery, student.Email).Scan(&id); err != nil {
+				return fmt.Errorf("line %d: %w", student.Line, err)
+			}
+			if _, err := tx.Exec(ctx, roleQuery, id, model.RoleStudent); err != nil {
+				return fmt.Errorf("line %d: %w", student.Line, err)
+			}
+			userID = &id
+			report[i].UserID = id
+		}
+
+		err := tx.QueryRow(ctx, studentQuery,
+			student.Student.FirstName,
+			student.Student.LastName,
+			student.Student.Gender,
+			student.Student.BirthDate,
+			student.Student.GroupID,
+			userID,
+		).Scan(&report[i].StudentID)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", student.Line, err)
+		}
+
+		if err := recordGroupChange(ctx, tx, report[i].StudentID, &student.Student.GroupID, now); err != nil {
+			return fmt.Errorf("line %d: %w", student.Line, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRecordLinked is returned when a student or staff record already belongs to an account
+var ErrRecordLinked = errors.New("record is already linked to an account")
+
+const invitationColumns = `id, student_id, staff_id, role, email, created_by, expires_at, used_at, used_by, created_at`
+
+// CreateInvitation stores an invitation for a student or staff record that is
+// not linked to an account yet. Earlier unused invitations for the same record
+// stop working. It returns pgx.ErrNoRows when the record does not exist and
+// ErrRecordLinked when it already has an account.
+func (r *Repository) CreateInvitation(req *model.CreateInvitationRequest, role, codeHash string, createdBy int, ttl time.Duration) (*model.Invitation, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	table, recordID := recordTarget(req.StudentID, req.StaffID)
+
+	var linkedUserID *int
+	lockQuery := `SELECT user_id FROM ` + table + ` WHERE id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, lockQuery, recordID).Scan(&linkedUserID); err != nil {
+		return nil, err
+	}
+	if linkedUserID != nil {
+		return nil, ErrRecordLinked
+	}
+
+	discardQuery := `
+	DELETE FROM invitations
+	WHERE used_at IS NULL AND (student_id = $1 OR staff_id = $2)
+	`
+	if _, err := tx.Exec(ctx, discardQuery, nullableID(req.StudentID), nullableID(req.StaffID)); err != nil {
+		return nil, err
+	}
+
+	query := `
+	INSERT INTO invitations (code_hash, student_id, staff_id, role, email, created_by, expires_at)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, CURRENT_TIMESTAMP + make_interval(secs => $7))
+	RETURNING ` + invitationColumns
+
+	invitation, err := scanInvitation(tx.QueryRow(ctx, query,
+		codeHash,
+		nullableID(req.StudentID),
+		nullableID(req.StaffID),
+		role,
+		req.Email,
+		createdBy,
+		ttl.Seconds(),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ListInvitations returns all invitations, newest first
+func (r *Repository) ListInvitations() ([]model.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations ORDER BY id DESC`
+
+	rows, err := r.pool.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []model.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// DeleteInvitation withdraws an unused invitation. It returns pgx.ErrNoRows
+// when the invitation does not exist or was already used.
+func (r *Repository) DeleteInvitation(id int) error {
+	query := `DELETE FROM invitations WHERE id = $1 AND used_at IS NULL RETURNING id`
+	return r.pool.QueryRow(context.Background(), query, id).Scan(&id)
+}
+
+// CreateInvitedUser creates an account from an invitation in one transaction:
+// the user is inserted, linked to the invited student or staff record, granted
+// the invitation's role and the invitation is marked used. It returns
+// pgx.ErrNoRows when the code is unknown, used, expired or bound to another
+// email, and ErrRecordLinked when the record got an account in the meantime.
+func (r *Repository) CreateInvitedUser(email, passwordHash, codeHash string) (*model.User, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	invitationQuery := `
+	SELECT id, student_id, staff_id, role FROM invitations
+	WHERE code_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	  AND (email IS NULL OR lower(email) = lower($2))
+	FOR UPDATE
+	`
+	var invitationID int
+	var studentID, staffID *int
+	var role string
+	if err := tx.QueryRow(ctx, invitationQuery, codeHash, email).Scan(&invitationID, &studentID, &staffID, &role); err != nil {
+		return nil, err
+	}
+
+	userQuery := `
+	INSERT INTO users (email, password_hash)
+	VALUES ($1, $2)
+	RETURNING id, email, password_hash, is_active, created_at, email_verified_at
+	`
+	var user model.User
+	err = tx.QueryRow(ctx, userQuery, email, passwordHash).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.EmailVerifiedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	table, recordID := recordTarget(deref(studentID), deref(staffID))
+	linkQuery := `UPDATE ` + table + ` SET user_id = $1 WHERE id = $2 AND user_id IS NULL`
+	tag, err := tx.Exec(ctx, linkQuery, user.ID, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrRecordLinked
+	}
+
+	roleQuery := `
+	INSERT INTO user_roles (user_id, role_id)
+	SELECT $1, id FROM roles WHERE name = $2
+	`
+	if _, err := tx.Exec(ctx, roleQuery, user.ID, role); err != nil {
+		return nil, err
+	}
+
+	usedQuery := `UPDATE invitations SET used_at = CURRENT_TIMESTAMP, used_by = $2 WHERE id = $1`
+	if _, err := tx.Exec(ctx, usedQuery, invitationID, user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// recordTarget returns the table and id of the student or staff record an
+// invitation or document belongs to
+func recordTarget(studentID, staffID int) (string, int) {
+	if studentID != 0 {
+		return "students", studentID
+	}
+	return "staff", staffID
+}
+
+// nullableID maps an unset id to NULL
+func nullableID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func deref(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+func scanInvitation(row pgx.Row) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.StudentID,
+		&invitation.StaffID,
+		&invitation.Role,
+		&invitation.Email,
+		&invitation.CreatedBy,
+		&invitation.ExpiresAt,
+		&invitation.UsedAt,
+		&invitation.UsedBy,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
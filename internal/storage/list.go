@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Page sizes of list endpoints
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// ErrInvalidSort is returned when a list is sorted by a field it does not support
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// listQuery accumulates the WHERE clause and arguments of a list query
+type listQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// where adds a condition; each "?" in it stands for arg
+func (q *listQuery) where(condition string, arg interface{}) {
+	q.args = append(q.args, arg)
+	q.conditions = append(q.conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(q.args))))
+}
+
+func (q *listQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// orderBy translates a sort parameter such as "group,-last_name" into an ORDER
+// BY clause. sortable maps the accepted field names to SQL expressions and must
+// contain "id", which breaks ties so pages are stable.
+func orderBy(sort string, sortable map[string]string, defaultSort string) (string, error) {
+	if sort == "" {
+		sort = defaultSort
+	}
+
+	var terms []string
+	sortedByID := false
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			field, direction = field[1:], "DESC"
+		}
+
+		expr, ok := sortable[field]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrInvalidSort, field)
+		}
+		terms = append(terms, expr+" "+direction)
+		sortedByID = sortedByID || field == "id"
+	}
+	if !sortedByID {
+		terms = append(terms, sortable["id"])
+	}
+
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// queryPage runs a list query and the count of all rows matching its filters.
+// columns and from are the SELECT list and FROM clause; scan reads one row.
+func queryPage[T any](r *Repository, columns, from string, q *listQuery, order string, params *model.ListParams, scan func(pgx.Rows, *T) error) (*model.Page[T], error) {
+	ctx := context.Background()
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+	offset := max(params.Offset, 0)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM ` + from + q.whereClause()
+	if err := r.pool.QueryRow(ctx, countQuery, q.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + columns + ` FROM ` + from + q.whereClause() + order +
+		fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+
+	rows, err := r.pool.Query(ctx, query, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		var item T
+		if err := scan(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &model.Page[T]{Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
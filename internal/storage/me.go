@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"university/internal/model"
+)
+
+// GetStudentByUserID retrieves the student record linked to a user account
+func (r *Repository) GetStudentByUserID(userID int) (*model.StudentProfile, error) {
+	query := `
+	SELECT s.id, s.first_name, s.last_name, s.gender, COALESCE(s.birth_date::text, ''),
+	       COALESCE(s.group_id, 0), COALESCE(g.name, ''),
+	       COALESCE(g.faculty_id, 0), COALESCE(f.name, '')
+	FROM students s
+	LEFT JOIN groups g ON s.group_id = g.id
+	LEFT JOIN faculties f ON g.faculty_id = f.id
+	WHERE s.user_id = $1
+	`
+
+	var student model.StudentProfile
+	err := r.pool.QueryRow(context.Background(), query, userID).Scan(
+		&student.ID,
+		&student.FirstName,
+		&student.LastName,
+		&student.Gender,
+		&student.BirthDate,
+		&student.GroupID,
+		&student.GroupName,
+		&student.FacultyID,
+		&student.FacultyName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
+// GetStaffByUserID retrieves the staff record linked to a user account
+func (r *Repository) GetStaffByUserID(userID int) (*model.StaffProfile, error) {
+	query := `
+	SELECT st.id, st.first_name, st.last_name, COALESCE(st.faculty_id, 0), COALESCE(f.name, ''),
+	       COALESCE(st.position, '')
+	FROM staff st
+	LEFT JOIN faculties f ON st.faculty_id = f.id
+	WHERE st.user_id = $1
+	`
+
+	var staff model.StaffProfile
+	err := r.pool.QueryRow(context.Background(), query, userID).Scan(
+		&staff.ID,
+		&staff.FirstName,
+		&staff.LastName,
+		&staff.FacultyID,
+		&staff.FacultyName,
+		&staff.Position,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+// GetFacultySchedule retrieves all classes scheduled for a faculty
+func (r *Repository) GetFacultySchedule(facultyID int) ([]model.ScheduleResponse, error) {
+	query := `
+	SELECT sc.id, f.name, g.name, s.name, sc.class_time
+	FROM schedule sc
+	JOIN faculties f ON sc.faculty_id = f.id
+	JOIN groups g ON sc.group_id = g.id
+	JOIN subjects s ON sc.subject_id = s.id
+	WHERE sc.faculty_id = $1
+	ORDER BY sc.id
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, facultyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []model.ScheduleResponse{}
+	for rows.Next() {
+		var schedule model.ScheduleResponse
+		if err := rows.Scan(
+			&schedule.ID,
+			&schedule.Faculty,
+			&schedule.Group,
+			&schedule.Subject,
+			&schedule.ClassTime,
+		); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// GetStudentAttendance retrieves every attendance record of a student, newest first
+func (r *Repository) GetStudentAttendance(studentID int) ([]model.AttendanceRecord, error) {
+	query := `
+	SELECT id, student_id, subject_id, visit_day, visited
+	FROM attendance
+	WHERE student_id = $1
+	ORDER BY visit_day DESC, id DESC
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []model.AttendanceRecord{}
+	for rows.Next() {
+		var record model.AttendanceRecord
+		if err := rows.Scan(
+			&record.ID,
+			&record.StudentID,
+			&record.SubjectID,
+			&record.VisitDay,
+			&record.Visited,
+		); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// GetStudentGrades retrieves every grade of a student with the subject name, newest first
+func (r *Repository) GetStudentGrades(studentID int) ([]model.GradeResponse, error) {
+	query := `
+	SELECT gr.id, gr.student_id, gr.subject_id, sub.name, gr.grade::float8, gr.graded_at
+	FROM grades gr
+	JOIN subjects sub ON gr.subject_id = sub.id
+	WHERE gr.student_id = $1
+	ORDER BY gr.graded_at DESC, gr.id DESC
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grades := []model.GradeResponse{}
+	for rows.Next() {
+		var grade model.GradeResponse
+		if err := rows.Scan(
+			&grade.ID,
+			&grade.StudentID,
+			&grade.SubjectID,
+			&grade.Subject,
+			&grade.Grade,
+			&grade.GradedAt,
+		); err != nil {
+			return nil, err
+		}
+		grades = append(grades, grade)
+	}
+	return grades, rows.Err()
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetUserMFA returns the user's TOTP secret and whether enrollment was confirmed.
+// It returns pgx.ErrNoRows when the user never started enrollment.
+func (r *Repository) GetUserMFA(userID int) (string, bool, error) {
+	query := `SELECT secret, enabled_at IS NOT NULL FROM user_mfa WHERE user_id = $1`
+
+	var secret string
+	var enabled bool
+	err := r.pool.QueryRow(context.Background(), query, userID).Scan(&secret, &enabled)
+	return secret, enabled, err
+}
+
+// SetPendingMFASecret stores a new, not yet confirmed TOTP secret for the user,
+// replacing any earlier unconfirmed one
+func (r *Repository) SetPendingMFASecret(userID int, secret string) error {
+	query := `
+	INSERT INTO user_mfa (user_id, secret)
+	VALUES ($1, $2)
+	ON CONFLICT (user_id)
+	DO UPDATE SET secret = EXCLUDED.secret, enabled_at = NULL, last_used_step = NULL,
+	              created_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.pool.Exec(context.Background(), query, userID, secret)
+	return err
+}
+
+// EnableMFA confirms the user's pending TOTP secret and replaces their recovery codes
+func (r *Repository) EnableMFA(userID int, step int64, codeHashes []string) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	UPDATE user_mfa SET enabled_at = CURRENT_TIMESTAMP, last_used_step = $2
+	WHERE user_id = $1 AND enabled_at IS NULL
+	`
+	tag, err := tx.Exec(ctx, query, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep records that a TOTP code for the given time step was used.
+// It reports false when that step or a later one was already used, so a code
+// cannot be replayed.
+func (r *Repository) UseTOTPStep(userID int, step int64) (bool, error) {
+	query := `
+	UPDATE user_mfa SET last_used_step = $2
+	WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+	tag, err := r.pool.Exec(context.Background(), query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores new ones
+func (r *Repository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO mfa_recovery_codes (user_id, code_hash)
+	SELECT $1, unnest($2::text[])
+	`
+	_, err := tx.Exec(ctx, query, userID, codeHashes)
+	return err
+}
+
+// UseRecoveryCode marks an unused recovery code as used.
+// It returns pgx.ErrNoRows when the code is unknown or was already used.
+func (r *Repository) UseRecoveryCode(userID int, codeHash string) error {
+	query := `
+	UPDATE mfa_recovery_codes SET used_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	tag, err := r.pool.Exec(context.Background(), query, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// DisableMFA removes the user's TOTP secret and recovery codes.
+// It returns pgx.ErrNoRows when the user has no two-factor setup.
+func (r *Repository) DisableMFA(userID int) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateMFAChallenge stores the hash of a pending second-factor login.
+// Expired challenges are pruned on the way.
+func (r *Repository) CreateMFAChallenge(userID int, tokenHash string, ttl time.Duration) error {
+	ctx := context.Background()
+
+	if _, err := r.pool.Exec(ctx, `DELETE FROM mfa_challenges WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO mfa_challenges (token_hash, user_id, expires_at)
+	VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3))
+	`
+	_, err := r.pool.Exec(ctx, query, tokenHash, userID, ttl.Seconds())
+	return err
+}
+
+// GetMFAChallenge returns the user of a pending second-factor login. It returns
+// pgx.ErrNoRows when the challenge is unknown, expired or out of attempts.
+func (r *Repository) GetMFAChallenge(tokenHash string, maxAttempts int) (int, error) {
+	query := `
+	SELECT user_id FROM mfa_challenges
+	WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP AND attempts < $2
+	`
+
+	var userID int
+	err := r.pool.QueryRow(context.Background(), query, tokenHash, maxAttempts).Scan(&userID)
+	return userID, err
+}
+
+// FailMFAChallenge counts a wrong code against a pending second-factor login
+func (r *Repository) FailMFAChallenge(tokenHash string) error {
+	query := `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE token_hash = $1`
+	_, err := r.pool.Exec(context.Background(), query, tokenHash)
+	return err
+}
+
+// DeleteMFAChallenge removes a completed second-factor login
+func (r *Repository) DeleteMFAChallenge(tokenHash string) error {
+	_, err := r.pool.Exec(context.Background(), `DELETE FROM mfa_challenges WHERE token_hash = $1`, tokenHash)
+	return err
+}
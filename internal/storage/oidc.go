@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"time"
+	"university/internal/model"
+)
+
+// CreateOIDCState stores a pending single sign-on login until the IdP redirects back.
+// Expired states are pruned on the way.
+func (r *Repository) CreateOIDCState(state, codeVerifier, nonce string, ttl time.Duration) error {
+	ctx := context.Background()
+
+	if _, err := r.pool.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO oidc_login_states (state, code_verifier, nonce, expires_at)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+	`
+	_, err := r.pool.Exec(ctx, query, state, codeVerifier, nonce, ttl.Seconds())
+	return err
+}
+
+// ConsumeOIDCState deletes a pending login and returns its PKCE code verifier and nonce.
+// It returns pgx.ErrNoRows when the state is unknown, already used or expired.
+func (r *Repository) ConsumeOIDCState(state string) (string, string, error) {
+	query := `
+	DELETE FROM oidc_login_states
+	WHERE state = $1 AND expires_at > CURRENT_TIMESTAMP
+	RETURNING code_verifier, nonce
+	`
+
+	var codeVerifier, nonce string
+	err := r.pool.QueryRow(context.Background(), query, state).Scan(&codeVerifier, &nonce)
+	return codeVerifier, nonce, err
+}
+
+// GetUserByIdentity retrieves the user linked to an external identity
+func (r *Repository) GetUserByIdentity(issuer, subject string) (*model.User, error) {
+	query := `
+	SELECT u.id, u.email, u.password_hash, u.is_active, u.created_at, u.email_verified_at
+	FROM users u
+	JOIN user_identities ui ON ui.user_id = u.id
+	WHERE ui.issuer = $1 AND ui.subject = $2
+	`
+
+	var user model.User
+	err := r.pool.QueryRow(context.Background(), query, issuer, subject).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.EmailVerifiedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// LinkIdentity links an external identity to a user, or records a new login
+// when the link already exists
+func (r *Repository) LinkIdentity(userID int, issuer, subject, email string) error {
+	query := `
+	INSERT INTO user_identities (user_id, issuer, subject, email, last_login_at)
+	VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+	ON CONFLICT (issuer, subject)
+	DO UPDATE SET email = EXCLUDED.email, last_login_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.pool.Exec(context.Background(), query, userID, issuer, subject, email)
+	return err
+}
+
+// CreateOIDCUser provisions a user without a password together with its external
+// identity. The email is marked verified when the identity provider vouches for it.
+func (r *Repository) CreateOIDCUser(email, issuer, subject string, emailVerified bool) (*model.User, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO users (email, password_hash, email_verified_at)
+	VALUES ($1, '', CASE WHEN $2 THEN CURRENT_TIMESTAMP END)
+	RETURNING id, email, password_hash, is_active, created_at, email_verified_at
+	`
+
+	var user model.User
+	err = tx.QueryRow(ctx, query, email, emailVerified).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.EmailVerifiedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	identityQuery := `
+	INSERT INTO user_identities (user_id, issuer, subject, email, last_login_at)
+	VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+	`
+	if _, err := tx.Exec(ctx, identityQuery, user.ID, issuer, subject, email); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
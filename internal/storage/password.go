@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// CreateOutboxMessage stores an outgoing email in the mail outbox
+func (r *Repository) CreateOutboxMessage(sender, recipient, subject, body string) error {
+	query := `
+	INSERT INTO mail_outbox (sender, recipient, subject, body)
+	VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.pool.Exec(context.Background(), query, sender, recipient, subject, body)
+	return err
+}
+
+// CreatePasswordResetToken stores the hash of a new password reset token
+func (r *Repository) CreatePasswordResetToken(userID int, tokenHash string, ttl time.Duration) error {
+	query := `
+	INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+	VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3))
+	`
+	_, err := r.pool.Exec(context.Background(), query, userID, tokenHash, ttl.Seconds())
+	return err
+}
+
+// ResetPassword consumes a valid reset token and sets the new password hash in one
+// transaction. All other outstanding reset tokens and refresh tokens of the user are
+// revoked. It returns pgx.ErrNoRows when the token is unknown, used or expired.
+func (r *Repository) ResetPassword(tokenHash, passwordHash string) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	SELECT user_id FROM password_reset_tokens
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	FOR UPDATE
+	`
+	var userID int
+	if err := tx.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, passwordHash, userID); err != nil {
+		return 0, err
+	}
+
+	usedQuery := `
+	UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND used_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, usedQuery, userID); err != nil {
+		return 0, err
+	}
+
+	revokeQuery := `
+	UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, revokeQuery, userID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// ChangePassword sets a new password hash for the user and revokes all of the
+// user's refresh tokens
+func (r *Repository) ChangePassword(userID int, passwordHash string) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, passwordHash, userID); err != nil {
+		return err
+	}
+
+	revokeQuery := `
+	UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, revokeQuery, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpgradePasswordHash replaces a password hash with a stronger hash of the same
+// password. It only applies while the stored hash is still oldHash, so it cannot
+// undo a concurrent password change.
+func (r *Repository) UpgradePasswordHash(userID int, oldHash, newHash string) error {
+	query := `UPDATE users SET password_hash = $3 WHERE id = $1 AND password_hash = $2`
+	_, err := r.pool.Exec(context.Background(), query, userID, oldHash, newHash)
+	return err
+}
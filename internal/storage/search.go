@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"university/internal/model"
+)
+
+// Search finds students, staff, groups and subjects whose names (and, for
+// people, emails) resemble the query, most similar first. Matching runs on
+// search_key, so Cyrillic and Latin spellings of a name find each other, and
+// tolerates typos through trigram word similarity. types limits the result
+// types; an empty slice searches all of them. Archived students are left out.
+func (r *Repository) Search(query string, types []string, limit int) ([]model.SearchResult, error) {
+	sql := `
+	WITH q AS (SELECT search_key($1) AS key)
+	SELECT type, id, title, subtitle, score::float8 FROM (
+		SELECT 'student' AS type, s.id,
+		       concat_ws(' ', s.first_name, s.last_name) AS title,
+		       concat_ws(' · ', g.name, u.email) AS subtitle,
+		       GREATEST(
+		           word_similarity(q.key, search_key(s.first_name || ' ' || s.last_name)),
+		           word_similarity(q.key, search_key(u.email))
+		       ) AS score
+		FROM students s
+		CROSS JOIN q
+		LEFT JOIN groups g ON g.id = s.group_id
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.archived_at IS NULL AND (
+		       q.key <% search_key(s.first_name || ' ' || s.last_name)
+		    OR search_key(s.first_name || ' ' || s.last_name) LIKE '%' || q.key || '%'
+		    OR q.key <% search_key(u.email)
+		    OR search_key(u.email) LIKE '%' || q.key || '%'
+		)
+
+		UNION ALL
+
+		SELECT 'staff', st.id,
+		       concat_ws(' ', st.first_name, st.last_name),
+		       concat_ws(' · ', st.position, f.name, u.email),
+		       GREATEST(
+		           word_similarity(q.key, search_key(st.first_name || ' ' || st.last_name)),
+		           word_similarity(q.key, search_key(u.email))
+		       )
+		FROM staff st
+		CROSS JOIN q
+		LEFT JOIN faculties f ON f.id = st.faculty_id
+		LEFT JOIN users u ON u.id = st.user_id
+		WHERE q.key <% search_key(st.first_name || ' ' || st.last_name)
+		   OR search_key(st.first_name || ' ' || st.last_name) LIKE '%' || q.key || '%'
+		   OR q.key <% search_key(u.email)
+		   OR search_key(u.email) LIKE '%' || q.key || '%'
+
+		UNION ALL
+
+		SELECT 'group', g.id, g.name, COALESCE(f.name, ''),
+		       word_similarity(q.key, search_key(g.name))
+		FROM groups g
+		CROSS JOIN q
+		LEFT JOIN faculties f ON f.id = g.faculty_id
+		WHERE q.key <% search_key(g.name)
+		   OR search_key(g.name) LIKE '%' || q.key || '%'
+
+		UNION ALL
+
+		SELECT 'subject', sub.id, sub.name, '',
+		       word_similarity(q.key, search_key(sub.name))
+		FROM subjects sub
+		CROSS JOIN q
+		WHERE q.key <% search_key(sub.name)
+		   OR search_key(sub.name) LIKE '%' || q.key || '%'
+	) results
+	WHERE cardinality($2::text[]) = 0 OR type = ANY($2)
+	ORDER BY score DESC, title, id
+	LIMIT $3
+	`
+
+	if types == nil {
+		types = []string{}
+	}
+
+	rows, err := r.pool.Query(context.Background(), sql, query, types, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []model.SearchResult{}
+	for rows.Next() {
+		var result model.SearchResult
+		if err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.Title,
+			&result.Subtitle,
+			&result.Score,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"university/internal/model"
+)
+
+// activeSessionCondition holds for sessions that were not revoked and still
+// have a usable refresh token, so revoking a refresh token family (logout,
+// password change, reuse detection) also ends its session
+const activeSessionCondition = `
+	s.revoked_at IS NULL AND EXISTS (
+		SELECT 1 FROM refresh_tokens rt
+		WHERE rt.family_id = s.family_id AND rt.revoked_at IS NULL AND rt.expires_at > CURRENT_TIMESTAMP
+	)`
+
+// CreateSession records a login for the given refresh token family
+func (r *Repository) CreateSession(userID int, familyID, userAgent, ipAddress string) (int, error) {
+	query := `
+	INSERT INTO sessions (user_id, family_id, user_agent, ip_address)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id
+	`
+
+	var id int
+	err := r.pool.QueryRow(context.Background(), query, userID, familyID, userAgent, ipAddress).Scan(&id)
+	return id, err
+}
+
+// TouchSessionByFamily records activity on the session of a refresh token family
+// and returns its ID. It returns pgx.ErrNoRows when the session was revoked.
+func (r *Repository) TouchSessionByFamily(familyID, userAgent, ipAddress string) (int, error) {
+	query := `
+	UPDATE sessions SET last_seen_at = CURRENT_TIMESTAMP, user_agent = $2, ip_address = $3
+	WHERE family_id = $1 AND revoked_at IS NULL
+	RETURNING id
+	`
+
+	var id int
+	err := r.pool.QueryRow(context.Background(), query, familyID, userAgent, ipAddress).Scan(&id)
+	return id, err
+}
+
+// IsSessionActive reports whether a session may still be used and records its
+// activity. last_seen_at is only written once a minute to spare the table.
+func (r *Repository) IsSessionActive(sessionID int) (bool, error) {
+	ctx := context.Background()
+
+	var active bool
+	query := `SELECT EXISTS (SELECT 1 FROM sessions s WHERE s.id = $1 AND` + activeSessionCondition + `)`
+	if err := r.pool.QueryRow(ctx, query, sessionID).Scan(&active); err != nil {
+		return false, err
+	}
+	if !active {
+		return false, nil
+	}
+
+	touchQuery := `
+	UPDATE sessions SET last_seen_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND last_seen_at < CURRENT_TIMESTAMP - INTERVAL '1 minute'
+	`
+	if _, err := r.pool.Exec(ctx, touchQuery, sessionID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first
+func (r *Repository) ListSessions(userID int) ([]model.Session, error) {
+	query := `
+	SELECT s.id, COALESCE(s.user_agent, ''), COALESCE(s.ip_address, ''), s.created_at, s.last_seen_at
+	FROM sessions s
+	WHERE s.user_id = $1 AND` + activeSessionCondition + `
+	ORDER BY s.last_seen_at DESC
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		var session model.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeSession ends one of the user's sessions and revokes its refresh tokens.
+// It returns pgx.ErrNoRows when the user has no such active session.
+func (r *Repository) RevokeSession(userID, sessionID int) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	RETURNING family_id
+	`
+	var familyID string
+	if err := tx.QueryRow(ctx, query, sessionID, userID).Scan(&familyID); err != nil {
+		return err
+	}
+
+	revokeQuery := `
+	UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+	WHERE family_id = $1 AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, revokeQuery, familyID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -3,8 +3,10 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 	"university/internal/model"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,6 +20,21 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 
 func (r *Repository) InitDB() error {
 	query := `
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+    -- search_key folds text to lower-case Latin so names entered in Cyrillic
+    -- (Russian and Kazakh letters) and in Latin match each other
+    CREATE OR REPLACE FUNCTION search_key(value TEXT) RETURNS TEXT AS $$
+        SELECT translate(
+            replace(replace(replace(replace(replace(replace(replace(replace(replace(
+                lower(COALESCE(value, '')),
+                'щ', 'shch'), 'ж', 'zh'), 'х', 'kh'), 'ц', 'ts'), 'ч', 'ch'),
+                'ш', 'sh'), 'ю', 'yu'), 'я', 'ya'), 'ё', 'yo'),
+            'абвгдезийклмнопрстуфыэәғқңөұүһіъь',
+            'abvgdeziyklmnoprstufyeagknouuhi'
+        )
+    $$ LANGUAGE SQL IMMUTABLE;
+
     CREATE TABLE IF NOT EXISTS faculties (
         id SERIAL PRIMARY KEY,
         name VARCHAR(50) NOT NULL
@@ -55,7 +72,10 @@ func (r *Repository) InitDB() error {
         gender VARCHAR(10),
         birth_date DATE,
         group_id INT REFERENCES groups(id),
-        user_id INT UNIQUE REFERENCES users(id) ON DELETE SET NULL
+        user_id INT UNIQUE REFERENCES users(id) ON DELETE SET NULL,
+        archived_at TIMESTAMP,
+        status VARCHAR(20) NOT NULL DEFAULT 'enrolled'
+            CHECK (status IN ('enrolled', 'on_leave', 'graduated', 'expelled', 'transferred'))
     );
 
     CREATE TABLE IF NOT EXISTS staff (
@@ -96,6 +116,266 @@ func (r *Repository) InitDB() error {
         graded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
     );
 
+    CREATE TABLE IF NOT EXISTS refresh_tokens (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        token_hash VARCHAR(64) UNIQUE NOT NULL,
+        family_id VARCHAR(32) NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        revoked_at TIMESTAMP,
+        replaced_by INT REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+
+    CREATE TABLE IF NOT EXISTS revoked_tokens (
+        jti VARCHAR(64) PRIMARY KEY,
+        expires_at TIMESTAMP NOT NULL
+    );
+
+    CREATE TABLE IF NOT EXISTS mail_outbox (
+        id SERIAL PRIMARY KEY,
+        sender VARCHAR(100) NOT NULL,
+        recipient VARCHAR(100) NOT NULL,
+        subject VARCHAR(200) NOT NULL,
+        body TEXT NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS password_reset_tokens (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        token_hash VARCHAR(64) UNIQUE NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        used_at TIMESTAMP,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    -- Accounts that existed before email verification was introduced are
+    -- treated as verified; this runs only when the column is first added
+    DO $$
+    BEGIN
+        IF NOT EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'email_verified_at'
+        ) THEN
+            ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+            UPDATE users SET email_verified_at = created_at;
+        END IF;
+    END $$;
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'enrolled'
+        CHECK (status IN ('enrolled', 'on_leave', 'graduated', 'expelled', 'transferred'));
+
+    CREATE TABLE IF NOT EXISTS email_verification_tokens (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        token_hash VARCHAR(64) UNIQUE NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        used_at TIMESTAMP,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS login_attempts (
+        key VARCHAR(150) PRIMARY KEY,
+        failures INT NOT NULL DEFAULT 0,
+        last_failure_at TIMESTAMP,
+        locked_until TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS api_keys (
+        id SERIAL PRIMARY KEY,
+        name VARCHAR(100) NOT NULL,
+        key_prefix VARCHAR(16) NOT NULL,
+        key_hash VARCHAR(64) UNIQUE NOT NULL,
+        scopes TEXT[] NOT NULL,
+        created_by INT REFERENCES users(id) ON DELETE SET NULL,
+        expires_at TIMESTAMP,
+        last_used_at TIMESTAMP,
+        revoked_at TIMESTAMP,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS oidc_login_states (
+        state TEXT PRIMARY KEY,
+        code_verifier TEXT NOT NULL,
+        nonce TEXT NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS user_identities (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        issuer TEXT NOT NULL,
+        subject TEXT NOT NULL,
+        email VARCHAR(255),
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        last_login_at TIMESTAMP,
+        UNIQUE (issuer, subject)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+
+    CREATE TABLE IF NOT EXISTS user_mfa (
+        user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+        secret TEXT NOT NULL,
+        enabled_at TIMESTAMP,
+        last_used_step BIGINT,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        code_hash VARCHAR(64) NOT NULL,
+        used_at TIMESTAMP,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+
+    CREATE TABLE IF NOT EXISTS mfa_challenges (
+        token_hash VARCHAR(64) PRIMARY KEY,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        attempts INT NOT NULL DEFAULT 0,
+        expires_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS sessions (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        family_id VARCHAR(32) UNIQUE NOT NULL,
+        user_agent TEXT,
+        ip_address VARCHAR(45),
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        revoked_at TIMESTAMP
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id);
+
+    CREATE TABLE IF NOT EXISTS impersonations (
+        id SERIAL PRIMARY KEY,
+        actor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        reason TEXT NOT NULL,
+        started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        expires_at TIMESTAMP NOT NULL,
+        ended_at TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS impersonation_requests (
+        id SERIAL PRIMARY KEY,
+        impersonation_id INT NOT NULL REFERENCES impersonations(id) ON DELETE CASCADE,
+        method VARCHAR(10) NOT NULL,
+        path TEXT NOT NULL,
+        status INT,
+        ip_address VARCHAR(45),
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_impersonation_requests_impersonation ON impersonation_requests(impersonation_id);
+
+    -- Teachers who were writing attendance and grades before assignments
+    -- existed keep the scheduled groups and subjects of their faculty; this
+    -- runs only when the table is first created
+    DO $$
+    BEGIN
+        IF to_regclass('teaching_assignments') IS NULL THEN
+            CREATE TABLE teaching_assignments (
+                id SERIAL PRIMARY KEY,
+                staff_id INT NOT NULL REFERENCES staff(id) ON DELETE CASCADE,
+                group_id INT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                subject_id INT NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                UNIQUE (staff_id, group_id, subject_id)
+            );
+
+            INSERT INTO teaching_assignments (staff_id, group_id, subject_id)
+            SELECT DISTINCT st.id, sc.group_id, sc.subject_id
+            FROM schedule sc
+            JOIN groups g ON g.id = sc.group_id
+            JOIN staff st ON st.faculty_id = g.faculty_id
+            JOIN user_roles ur ON ur.user_id = st.user_id
+            JOIN roles r ON r.id = ur.role_id
+            WHERE r.name = 'TEACHER';
+        END IF;
+    END $$;
+
+    CREATE TABLE IF NOT EXISTS invitations (
+        id SERIAL PRIMARY KEY,
+        code_hash VARCHAR(64) UNIQUE NOT NULL,
+        student_id INT REFERENCES students(id) ON DELETE CASCADE,
+        staff_id INT REFERENCES staff(id) ON DELETE CASCADE,
+        role VARCHAR(20) NOT NULL,
+        email VARCHAR(255),
+        created_by INT REFERENCES users(id) ON DELETE SET NULL,
+        expires_at TIMESTAMP NOT NULL,
+        used_at TIMESTAMP,
+        used_by INT REFERENCES users(id) ON DELETE SET NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        CHECK ((student_id IS NULL) <> (staff_id IS NULL))
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_students_name_search ON students USING GIN (search_key(first_name || ' ' || last_name) gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_staff_name_search ON staff USING GIN (search_key(first_name || ' ' || last_name) gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_users_email_search ON users USING GIN (search_key(email) gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_groups_name_search ON groups USING GIN (search_key(name) gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_subjects_name_search ON subjects USING GIN (search_key(name) gin_trgm_ops);
+
+    CREATE TABLE IF NOT EXISTS student_group_history (
+        id SERIAL PRIMARY KEY,
+        student_id INT NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+        group_id INT REFERENCES groups(id),
+        valid_from DATE,
+        valid_to DATE,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_student_group_history_student ON student_group_history(student_id, valid_from);
+    CREATE INDEX IF NOT EXISTS idx_student_group_history_group ON student_group_history(group_id);
+
+    -- Students created before history was kept get an open period of unknown start
+    INSERT INTO student_group_history (student_id, group_id)
+    SELECT s.id, s.group_id FROM students s
+    WHERE NOT EXISTS (SELECT 1 FROM student_group_history h WHERE h.student_id = s.id);
+
+    CREATE TABLE IF NOT EXISTS student_status_changes (
+        id SERIAL PRIMARY KEY,
+        student_id INT NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+        from_status VARCHAR(20) NOT NULL,
+        to_status VARCHAR(20) NOT NULL,
+        reason TEXT NOT NULL,
+        changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_student_status_changes_student ON student_status_changes(student_id);
+
+    CREATE TABLE IF NOT EXISTS documents (
+        id SERIAL PRIMARY KEY,
+        student_id INT REFERENCES students(id) ON DELETE CASCADE,
+        staff_id INT REFERENCES staff(id) ON DELETE CASCADE,
+        kind VARCHAR(30) NOT NULL,
+        file_name VARCHAR(255) NOT NULL,
+        content_type VARCHAR(100) NOT NULL,
+        size_bytes BIGINT NOT NULL,
+        sha256 VARCHAR(64) NOT NULL,
+        storage_key VARCHAR(255) UNIQUE NOT NULL,
+        uploaded_by INT REFERENCES users(id) ON DELETE SET NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        CHECK ((student_id IS NULL) <> (staff_id IS NULL))
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_documents_student ON documents(student_id);
+    CREATE INDEX IF NOT EXISTS idx_documents_staff ON documents(staff_id);
+
+    INSERT INTO roles (name) VALUES ('ADMIN'), ('TEACHER'), ('STUDENT')
+    ON CONFLICT (name) DO NOTHING;
+
     `
 
 	_, err := r.pool.Exec(context.Background(), query)
@@ -122,16 +402,24 @@ func (r *Repository) CreateStudent(req *model.CreateStudentRequest) (*model.Stud
 		groupID = id
 	}
 
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 	INSERT INTO students (first_name, last_name, gender, birth_date, group_id)
 	VALUES ($1, $2, $3, $4, $5)
 	RETURNING id, first_name, last_name, gender, COALESCE(birth_date::text, ''),
-	          (SELECT name FROM groups WHERE id = $5)
+	          (SELECT name FROM groups WHERE id = $5), status
 	`
 
 	var student model.StudentResponse
-	err := r.pool.QueryRow(
-		context.Background(),
+	err = tx.QueryRow(
+		ctx,
 		query,
 		req.FirstName,
 		req.LastName,
@@ -145,14 +433,25 @@ func (r *Repository) CreateStudent(req *model.CreateStudentRequest) (*model.Stud
 		&student.Gender,
 		&student.BirthDate,
 		&student.GroupName,
+		&student.Status,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := recordGroupChange(ctx, tx, student.ID, &groupID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &student, nil
 }
 
-func (r *Repository) UpdateStudent(id string, req *model.UpdateStudentRequest) (*model.StudentResponse, error) {
+// UpdateStudent changes the given fields of a student. A group change is
+// recorded in the student's group history as of groupEffective.
+func (r *Repository) UpdateStudent(id string, req *model.UpdateStudentRequest, groupEffective time.Time) (*model.StudentResponse, error) {
 	// Build dynamic update query
 	query := `UPDATE students SET `
 	args := []interface{}{}
@@ -198,33 +497,54 @@ func (r *Repository) UpdateStudent(id string, req *model.UpdateStudentRequest) (
 	}
 
 	query = query[:len(query)-2]
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, first_name, last_name, gender, COALESCE(birth_date::text, ''), (SELECT name FROM groups g WHERE g.id = students.group_id)", argNum)
+	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, first_name, last_name, gender, COALESCE(birth_date::text, ''), (SELECT name FROM groups g WHERE g.id = students.group_id), status, archived_at, group_id", argNum)
 	args = append(args, id)
 
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var oldGroupID *int
+	if err := tx.QueryRow(ctx, `SELECT group_id FROM students WHERE id = $1 FOR UPDATE`, id).Scan(&oldGroupID); err != nil {
+		return nil, err
+	}
+
 	var student model.StudentResponse
-	err := r.pool.QueryRow(context.Background(), query, args...).Scan(
+	var newGroupID *int
+	err = tx.QueryRow(ctx, query, args...).Scan(
 		&student.ID,
 		&student.FirstName,
 		&student.LastName,
 		&student.Gender,
 		&student.BirthDate,
 		&student.GroupName,
+		&student.Status,
+		&student.ArchivedAt,
+		&newGroupID,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return &student, nil
-}
 
-func (r *Repository) DeleteStudent(id string) error {
-	query := `DELETE FROM students WHERE id = $1`
-	_, err := r.pool.Exec(context.Background(), query, id)
-	return err
+	if deref(oldGroupID) != deref(newGroupID) {
+		if err := recordGroupChange(ctx, tx, student.ID, newGroupID, groupEffective); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &student, nil
 }
 
 func (r *Repository) GetStudentByID(id string) (*model.StudentResponse, error) {
 	query := `
-	SELECT s.id, s.first_name, s.last_name, s.gender, COALESCE(s.birth_date::text, ''), COALESCE(g.name, '')
+	SELECT s.id, s.first_name, s.last_name, s.gender, COALESCE(s.birth_date::text, ''), COALESCE(g.name, ''), s.status, s.archived_at
 	FROM students s
 	LEFT JOIN groups g ON s.group_id = g.id
 	WHERE s.id = $1
@@ -243,6 +563,8 @@ func (r *Repository) GetStudentByID(id string) (*model.StudentResponse, error) {
 		&student.Gender,
 		&student.BirthDate,
 		&student.GroupName,
+		&student.Status,
+		&student.ArchivedAt,
 	)
 
 	if err != nil {
@@ -252,38 +574,63 @@ func (r *Repository) GetStudentByID(id string) (*model.StudentResponse, error) {
 	return &student, nil
 }
 
-func (r *Repository) GetAllStudents() ([]model.StudentListResponse, error) {
-	query := `
-	SELECT s.id, s.first_name, s.last_name,
+func (r *Repository) GetAllStudents(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.StudentListResponse], error) {
+	columns := `s.id, s.first_name, s.last_name,
 	       COALESCE(g.name, '') AS group_name,
-	       COALESCE(u.email, '') AS email
-	FROM students s
-	LEFT JOIN groups g ON s.group_id = g.id
-	LEFT JOIN users u ON s.user_id = u.id
-	`
+	       COALESCE(u.email, '') AS email,
+	       s.status, s.archived_at`
 
-	rows, err := r.pool.Query(context.Background(), query)
+	q, order, err := studentListQuery(filter, params.Sort)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var students []model.StudentListResponse
-	for rows.Next() {
-		var student model.StudentListResponse
-		if err := rows.Scan(
+	return queryPage(r, columns, studentListFrom, q, order, params, func(rows pgx.Rows, student *model.StudentListResponse) error {
+		return rows.Scan(
 			&student.ID,
 			&student.FirstName,
 			&student.LastName,
 			&student.GroupName,
 			&student.Email,
-		); err != nil {
-			return nil, err
-		}
-		students = append(students, student)
+			&student.Status,
+			&student.ArchivedAt,
+		)
+	})
+}
+
+// studentListFrom joins students to the tables their lists and exports read
+const studentListFrom = `students s
+	LEFT JOIN groups g ON s.group_id = g.id
+	LEFT JOIN faculties f ON g.faculty_id = f.id
+	LEFT JOIN users u ON s.user_id = u.id`
+
+// studentListQuery builds the filters and order shared by the student list
+// and export. Archived students are left out unless filter.Archived asks for
+// them alone, and only enrolled students are listed unless filter.Status
+// names another status or "all".
+func studentListQuery(filter *model.ListFilter, sort string) (*listQuery, string, error) {
+	var q listQuery
+	q.where("(s.archived_at IS NOT NULL) = ?", filter.Archived)
+	if status := studentStatus(filter.Status); status != "" {
+		q.where("s.status = ?", status)
+	}
+	if filter.GroupID != 0 {
+		q.where("s.group_id = ?", filter.GroupID)
+	}
+	if filter.FacultyID != 0 {
+		q.where("g.faculty_id = ?", filter.FacultyID)
 	}
 
-	return students, rows.Err()
+	order, err := orderBy(sort, map[string]string{
+		"id":         "s.id",
+		"first_name": "s.first_name",
+		"last_name":  "s.last_name",
+		"group":      "g.name",
+	}, "id")
+	if err != nil {
+		return nil, "", err
+	}
+	return &q, order, nil
 }
 
 func (r *Repository) CreateSchedule(req *model.CreateScheduleRequest) (*model.ScheduleResponse, error) {
@@ -400,41 +747,45 @@ func (r *Repository) GetScheduleByID(id string) (*model.ScheduleResponse, error)
 	return &schedule, nil
 }
 
-func (r *Repository) GetAllSchedules() ([]model.ScheduleResponse, error) {
-	var err error
-
-	query := `
-	SELECT sc.id, f.name, g.name, s.name, sc.class_time
-	FROM schedule sc
+// GetAllSchedules returns a page of classes, optionally of one group, faculty
+// or subject. Sortable by id, class_time, group and subject.
+func (r *Repository) GetAllSchedules(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.ScheduleResponse], error) {
+	columns := `sc.id, f.name, g.name, s.name, sc.class_time`
+	from := `schedule sc
 	JOIN faculties f ON sc.faculty_id = f.id
 	JOIN groups g ON sc.group_id = g.id
-	JOIN subjects s ON sc.subject_id = s.id
-	`
+	JOIN subjects s ON sc.subject_id = s.id`
 
-	rows, err := r.pool.Query(context.Background(), query)
+	var q listQuery
+	if filter.GroupID != 0 {
+		q.where("sc.group_id = ?", filter.GroupID)
+	}
+	if filter.FacultyID != 0 {
+		q.where("sc.faculty_id = ?", filter.FacultyID)
+	}
+	if filter.SubjectID != 0 {
+		q.where("sc.subject_id = ?", filter.SubjectID)
+	}
+
+	order, err := orderBy(params.Sort, map[string]string{
+		"id":         "sc.id",
+		"class_time": "sc.class_time",
+		"group":      "g.name",
+		"subject":    "s.name",
+	}, "id")
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var schedules []model.ScheduleResponse
 
-	for rows.Next() {
-		var schedule model.ScheduleResponse
-		err := rows.Scan(
+	return queryPage(r, columns, from, &q, order, params, func(rows pgx.Rows, schedule *model.ScheduleResponse) error {
+		return rows.Scan(
 			&schedule.ID,
 			&schedule.Faculty,
 			&schedule.Group,
 			&schedule.Subject,
 			&schedule.ClassTime,
 		)
-		if err != nil {
-			return nil, err
-		}
-		schedules = append(schedules, schedule)
-	}
-
-	return schedules, nil
+	})
 }
 
 func (r *Repository) GetGroupSchedule(groupID string) ([]model.ScheduleResponse, error) {
@@ -492,22 +843,16 @@ func (r *Repository) GetFacultyByID(id string) (*model.FacultyResponse, error) {
 	return &faculty, nil
 }
 
-func (r *Repository) GetAllFaculties() ([]model.FacultyResponse, error) {
-	query := `SELECT id, name FROM faculties ORDER BY id`
-	rows, err := r.pool.Query(context.Background(), query)
+// GetAllFaculties returns a page of faculties, sortable by id and name
+func (r *Repository) GetAllFaculties(params *model.ListParams) (*model.Page[model.FacultyResponse], error) {
+	order, err := orderBy(params.Sort, map[string]string{"id": "id", "name": "name"}, "id")
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var faculties []model.FacultyResponse
-	for rows.Next() {
-		var f model.FacultyResponse
-		if err := rows.Scan(&f.ID, &f.Name); err != nil {
-			return nil, err
-		}
-		faculties = append(faculties, f)
-	}
-	return faculties, rows.Err()
+
+	return queryPage(r, `id, name`, `faculties`, &listQuery{}, order, params, func(rows pgx.Rows, f *model.FacultyResponse) error {
+		return rows.Scan(&f.ID, &f.Name)
+	})
 }
 
 func (r *Repository) CreateGroup(req *model.CreateGroupRequest) (*model.GroupResponse, error) {
@@ -541,26 +886,30 @@ func (r *Repository) GetGroupByID(id string) (*model.GroupResponse, error) {
 	return &group, nil
 }
 
-func (r *Repository) GetAllGroups() ([]model.GroupResponse, error) {
-	query := `
-	SELECT g.id, g.name, g.faculty_id, COALESCE(f.name, '') FROM groups g
-	LEFT JOIN faculties f ON g.faculty_id = f.id
-	ORDER BY g.id
-	`
-	rows, err := r.pool.Query(context.Background(), query)
+// GetAllGroups returns a page of groups, optionally of one faculty.
+// Sortable by id, name and faculty.
+func (r *Repository) GetAllGroups(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.GroupResponse], error) {
+	columns := `g.id, g.name, g.faculty_id, COALESCE(f.name, '')`
+	from := `groups g
+	LEFT JOIN faculties f ON g.faculty_id = f.id`
+
+	var q listQuery
+	if filter.FacultyID != 0 {
+		q.where("g.faculty_id = ?", filter.FacultyID)
+	}
+
+	order, err := orderBy(params.Sort, map[string]string{
+		"id":      "g.id",
+		"name":    "g.name",
+		"faculty": "f.name",
+	}, "id")
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var groups []model.GroupResponse
-	for rows.Next() {
-		var g model.GroupResponse
-		if err := rows.Scan(&g.ID, &g.Name, &g.FacultyID, &g.FacultyName); err != nil {
-			return nil, err
-		}
-		groups = append(groups, g)
-	}
-	return groups, rows.Err()
+
+	return queryPage(r, columns, from, &q, order, params, func(rows pgx.Rows, g *model.GroupResponse) error {
+		return rows.Scan(&g.ID, &g.Name, &g.FacultyID, &g.FacultyName)
+	})
 }
 
 func (r *Repository) CreateSubject(req *model.CreateSubjectRequest) (*model.SubjectResponse, error) {
@@ -583,22 +932,16 @@ func (r *Repository) GetSubjectByID(id string) (*model.SubjectResponse, error) {
 	return &subject, nil
 }
 
-func (r *Repository) GetAllSubjects() ([]model.SubjectResponse, error) {
-	query := `SELECT id, name FROM subjects ORDER BY id`
-	rows, err := r.pool.Query(context.Background(), query)
+// GetAllSubjects returns a page of subjects, sortable by id and name
+func (r *Repository) GetAllSubjects(params *model.ListParams) (*model.Page[model.SubjectResponse], error) {
+	order, err := orderBy(params.Sort, map[string]string{"id": "id", "name": "name"}, "id")
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var subjects []model.SubjectResponse
-	for rows.Next() {
-		var s model.SubjectResponse
-		if err := rows.Scan(&s.ID, &s.Name); err != nil {
-			return nil, err
-		}
-		subjects = append(subjects, s)
-	}
-	return subjects, rows.Err()
+
+	return queryPage(r, `id, name`, `subjects`, &listQuery{}, order, params, func(rows pgx.Rows, s *model.SubjectResponse) error {
+		return rows.Scan(&s.ID, &s.Name)
+	})
 }
 
 func (r *Repository) CreateAttendanceRecord(req *model.CreateAttendanceRequest) (*model.AttendanceRecord, error) {
@@ -701,112 +1044,103 @@ func (r *Repository) GetAttendanceByID(id string) (*model.AttendanceRecord, erro
 	return &record, nil
 }
 
-func (r *Repository) GetAllAttendanceRecords() ([]model.AttendanceRecord, error) {
-	query := `SELECT id, student_id, subject_id, visit_day, visited FROM attendance`
-	rows, err := r.pool.Query(context.Background(), query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// GetAllAttendanceRecords returns a page of attendance records, optionally of
+// one student, subject or group and between two visit days.
+// Sortable by id, visit_day, student_id and subject_id.
+func (r *Repository) GetAllAttendanceRecords(filter *model.ListFilter, params *model.ListParams) (*model.Page[model.AttendanceRecord], error) {
+	columns := `a.id, a.student_id, a.subject_id, a.visit_day, a.visited`
+	from := `attendance a`
 
-	var records []model.AttendanceRecord
-	for rows.Next() {
-		var record model.AttendanceRecord
-		if err := rows.Scan(
-			&record.ID,
-			&record.StudentID,
-			&record.SubjectID,
-			&record.VisitDay,
-			&record.Visited,
-		); err != nil {
-			return nil, err
-		}
-		records = append(records, record)
+	var q listQuery
+	if filter.StudentID != 0 {
+		q.where("a.student_id = ?", filter.StudentID)
+	}
+	if filter.SubjectID != 0 {
+		q.where("a.subject_id = ?", filter.SubjectID)
+	}
+	if filter.GroupID != 0 {
+		// Students count towards the group they were in on the visit day
+		q.where(`EXISTS (
+			SELECT 1 FROM student_group_history h
+			WHERE h.student_id = a.student_id AND h.group_id = ?
+			  AND (h.valid_from IS NULL OR h.valid_from <= a.visit_day)
+			  AND (h.valid_to IS NULL OR h.valid_to > a.visit_day)
+		)`, filter.GroupID)
+	}
+	// A single student's records are shown whatever their status, unless
+	// one is asked for
+	if status := studentStatus(filter.Status); status != "" && (filter.StudentID == 0 || filter.Status != "") {
+		q.where("a.student_id IN (SELECT id FROM students WHERE status = ?)", status)
+	}
+	if filter.From != "" {
+		q.where("a.visit_day >= ?::date", filter.From)
+	}
+	if filter.To != "" {
+		q.where("a.visit_day <= ?::date", filter.To)
 	}
-	return records, rows.Err()
-}
-
-func (r *Repository) GetAttendanceRecordsByStudentID(studentID string) ([]model.AttendanceRecord, error) {
-	query := `
-	SELECT id, student_id, subject_id, visit_day, visited
-	FROM attendance
-	WHERE student_id = $1
-	LIMIT 5
-	`
 
-	rows, err := r.pool.Query(context.Background(), query, studentID)
+	order, err := orderBy(params.Sort, map[string]string{
+		"id":         "a.id",
+		"visit_day":  "a.visit_day",
+		"student_id": "a.student_id",
+		"subject_id": "a.subject_id",
+	}, "id")
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var records []model.AttendanceRecord
-	for rows.Next() {
-		var record model.AttendanceRecord
-		err := rows.Scan(
+	return queryPage(r, columns, from, &q, order, params, func(rows pgx.Rows, record *model.AttendanceRecord) error {
+		return rows.Scan(
 			&record.ID,
 			&record.StudentID,
 			&record.SubjectID,
 			&record.VisitDay,
 			&record.Visited,
 		)
-		if err != nil {
-			return nil, err
-		}
-		records = append(records, record)
-	}
-
-	return records, nil
+	})
 }
 
-func (r *Repository) GetAttendanceRecordsBySubjectID(subjectID string) ([]model.AttendanceRecord, error) {
+// GetUserByEmail retrieves a user by email
+func (r *Repository) GetUserByEmail(email string) (*model.User, error) {
 	query := `
-	SELECT id, student_id, subject_id, visit_day, visited
-	FROM attendance
-	WHERE subject_id = $1
-	LIMIT 5
+	SELECT id, email, password_hash, is_active, created_at, email_verified_at
+	FROM users
+	WHERE email = $1
 	`
 
-	rows, err := r.pool.Query(context.Background(), query, subjectID)
+	var user model.User
+	err := r.pool.QueryRow(context.Background(), query, email).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.EmailVerifiedAt,
+	)
+
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var records []model.AttendanceRecord
-	for rows.Next() {
-		var record model.AttendanceRecord
-		err := rows.Scan(
-			&record.ID,
-			&record.StudentID,
-			&record.SubjectID,
-			&record.VisitDay,
-			&record.Visited,
-		)
-		if err != nil {
-			return nil, err
-		}
-		records = append(records, record)
-	}
 
-	return records, nil
+	return &user, nil
 }
 
-// GetUserByEmail retrieves a user by email
-func (r *Repository) GetUserByEmail(email string) (*model.User, error) {
+// GetUserAccountByID retrieves a user account (including password hash) by ID
+func (r *Repository) GetUserAccountByID(userID int) (*model.User, error) {
 	query := `
-	SELECT id, email, password_hash, is_active, created_at
+	SELECT id, email, password_hash, is_active, created_at, email_verified_at
 	FROM users
-	WHERE email = $1
+	WHERE id = $1
 	`
 
 	var user model.User
-	err := r.pool.QueryRow(context.Background(), query, email).Scan(
+	err := r.pool.QueryRow(context.Background(), query, userID).Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.IsActive,
 		&user.CreatedAt,
+		&user.EmailVerifiedAt,
 	)
 
 	if err != nil {
@@ -821,7 +1155,7 @@ func (r *Repository) CreateUser(email, passwordHash string) (*model.User, error)
 	query := `
 	INSERT INTO users (email, password_hash)
 	VALUES ($1, $2)
-	RETURNING id, email, password_hash, is_active, created_at
+	RETURNING id, email, password_hash, is_active, created_at, email_verified_at
 	`
 
 	var user model.User
@@ -836,6 +1170,7 @@ func (r *Repository) CreateUser(email, passwordHash string) (*model.User, error)
 		&user.PasswordHash,
 		&user.IsActive,
 		&user.CreatedAt,
+		&user.EmailVerifiedAt,
 	)
 
 	if err != nil {
@@ -848,7 +1183,7 @@ func (r *Repository) CreateUser(email, passwordHash string) (*model.User, error)
 // GetUserByID retrieves a user by ID with roles
 func (r *Repository) GetUserByID(userID string) (*model.UserResponse, error) {
 	query := `
-	SELECT u.id, u.email, u.is_active, u.created_at
+	SELECT u.id, u.email, u.is_active, u.created_at, u.email_verified_at
 	FROM users u
 	WHERE u.id = $1
 	`
@@ -859,6 +1194,7 @@ func (r *Repository) GetUserByID(userID string) (*model.UserResponse, error) {
 		&user.Email,
 		&user.IsActive,
 		&user.CreatedAt,
+		&user.EmailVerifiedAt,
 	)
 
 	if err != nil {
@@ -866,42 +1202,56 @@ func (r *Repository) GetUserByID(userID string) (*model.UserResponse, error) {
 	}
 
 	// Get user roles
-	rolesQuery := `
+	roles, err := r.GetUserRoles(userID)
+	if err != nil {
+		user.Roles = []string{}
+		return &user, nil
+	}
+	user.Roles = roles
+
+	return &user, nil
+}
+
+// GetUserRoles retrieves the names of all roles assigned to a user
+func (r *Repository) GetUserRoles(userID string) ([]string, error) {
+	query := `
 	SELECT r.name
 	FROM roles r
 	JOIN user_roles ur ON r.id = ur.role_id
 	WHERE ur.user_id = $1
 	`
 
-	rows, err := r.pool.Query(context.Background(), rolesQuery, userID)
+	rows, err := r.pool.Query(context.Background(), query, userID)
 	if err != nil {
-		user.Roles = []string{}
-		return &user, nil
+		return nil, err
 	}
 	defer rows.Close()
 
-	user.Roles = []string{}
+	roles := []string{}
 	for rows.Next() {
 		var roleName string
 		if err := rows.Scan(&roleName); err != nil {
-			continue
+			return nil, err
 		}
-		user.Roles = append(user.Roles, roleName)
+		roles = append(roles, roleName)
 	}
 
-	return &user, nil
+	return roles, rows.Err()
 }
 
-func (r *Repository) GetStudentsGPA() ([]model.StudentGPAResponse, error) {
+// GetStudentsGPA averages the grades of current students with the given
+// enrollment status, as for ListFilter.Status
+func (r *Repository) GetStudentsGPA(status string) ([]model.StudentGPAResponse, error) {
 	query := `
 	SELECT s.id,
 	       ROUND(AVG(g.grade)::NUMERIC, 2) AS gpa
 	FROM students s
 	INNER JOIN grades g ON g.student_id = s.id
+	WHERE s.archived_at IS NULL AND ($1 = '' OR s.status = $1)
 	GROUP BY s.id
 	`
 
-	rows, err := r.pool.Query(context.Background(), query)
+	rows, err := r.pool.Query(context.Background(), query, studentStatus(status))
 	if err != nil {
 		return nil, err
 	}
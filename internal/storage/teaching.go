@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"university/internal/model"
+)
+
+// ErrFacultyMismatch is returned when a group is assigned to staff of another faculty
+var ErrFacultyMismatch = errors.New("group belongs to another faculty than the staff member")
+
+// TeachesStudentSubject reports whether the staff member of a user teaches the
+// subject to the student's group, within the staff member's own faculty
+func (r *Repository) TeachesStudentSubject(userID, studentID, subjectID int) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1
+		FROM staff st
+		JOIN teaching_assignments ta ON ta.staff_id = st.id
+		JOIN groups g ON g.id = ta.group_id
+		JOIN students s ON s.group_id = ta.group_id
+		WHERE st.user_id = $1 AND s.id = $2 AND ta.subject_id = $3
+		  AND g.faculty_id = st.faculty_id
+	)
+	`
+
+	var teaches bool
+	err := r.pool.QueryRow(context.Background(), query, userID, studentID, subjectID).Scan(&teaches)
+	return teaches, err
+}
+
+// CreateTeachingAssignment assigns a subject and group to a staff member. The
+// group must belong to the staff member's faculty, otherwise ErrFacultyMismatch
+// is returned.
+func (r *Repository) CreateTeachingAssignment(req *model.CreateTeachingAssignmentRequest) (*model.TeachingAssignment, error) {
+	ctx := context.Background()
+
+	var sameFaculty bool
+	checkQuery := `
+	SELECT COALESCE(g.faculty_id = st.faculty_id, false)
+	FROM staff st, groups g
+	WHERE st.id = $1 AND g.id = $2
+	`
+	if err := r.pool.QueryRow(ctx, checkQuery, req.StaffID, req.GroupID).Scan(&sameFaculty); err != nil {
+		return nil, err
+	}
+	if !sameFaculty {
+		return nil, ErrFacultyMismatch
+	}
+
+	query := `
+	WITH ta AS (
+		INSERT INTO teaching_assignments (staff_id, group_id, subject_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (staff_id, group_id, subject_id)
+		DO UPDATE SET staff_id = EXCLUDED.staff_id
+		RETURNING id, staff_id, group_id, subject_id, created_at
+	)
+	SELECT ta.id, ta.staff_id, ta.group_id, g.name, ta.subject_id, sub.name, ta.created_at
+	FROM ta
+	JOIN groups g ON g.id = ta.group_id
+	JOIN subjects sub ON sub.id = ta.subject_id
+	`
+
+	var assignment model.TeachingAssignment
+	err := r.pool.QueryRow(ctx, query, req.StaffID, req.GroupID, req.SubjectID).Scan(
+		&assignment.ID,
+		&assignment.StaffID,
+		&assignment.GroupID,
+		&assignment.Group,
+		&assignment.SubjectID,
+		&assignment.Subject,
+		&assignment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// ListTeachingAssignments returns teaching assignments, optionally of one staff member
+func (r *Repository) ListTeachingAssignments(staffID int) ([]model.TeachingAssignment, error) {
+	query := `
+	SELECT ta.id, ta.staff_id, ta.group_id, g.name, ta.subject_id, sub.name, ta.created_at
+	FROM teaching_assignments ta
+	JOIN groups g ON g.id = ta.group_id
+	JOIN subjects sub ON sub.id = ta.subject_id
+	WHERE $1 = 0 OR ta.staff_id = $1
+	ORDER BY ta.staff_id, g.name, sub.name
+	`
+
+	rows, err := r.pool.Query(context.Background(), query, staffID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []model.TeachingAssignment{}
+	for rows.Next() {
+		var assignment model.TeachingAssignment
+		if err := rows.Scan(
+			&assignment.ID,
+			&assignment.StaffID,
+			&assignment.GroupID,
+			&assignment.Group,
+			&assignment.SubjectID,
+			&assignment.Subject,
+			&assignment.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// DeleteTeachingAssignment removes a teaching assignment.
+// It returns pgx.ErrNoRows when no such assignment exists.
+func (r *Repository) DeleteTeachingAssignment(id int) error {
+	var deleted int
+	query := `DELETE FROM teaching_assignments WHERE id = $1 RETURNING id`
+	return r.pool.QueryRow(context.Background(), query, id).Scan(&deleted)
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// GetLoginLock returns the longest remaining lockout among the given throttle keys,
+// or zero when none of them is locked
+func (r *Repository) GetLoginLock(keys []string) (time.Duration, error) {
+	query := `
+	SELECT COALESCE(MAX(EXTRACT(EPOCH FROM (locked_until - CURRENT_TIMESTAMP))), 0)::float8
+	FROM login_attempts
+	WHERE key = ANY($1) AND locked_until > CURRENT_TIMESTAMP
+	`
+	var seconds float64
+	if err := r.pool.QueryRow(context.Background(), query, keys).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RecordLoginFailure increments the failure counter of a throttle key and returns it.
+// Counters whose last failure is older than window start again from one.
+func (r *Repository) RecordLoginFailure(key string, window time.Duration) (int, error) {
+	query := `
+	INSERT INTO login_attempts (key, failures, last_failure_at)
+	VALUES ($1, 1, CURRENT_TIMESTAMP)
+	ON CONFLICT (key) DO UPDATE SET
+	    failures = CASE
+	        WHEN login_attempts.last_failure_at < CURRENT_TIMESTAMP - make_interval(secs => $2) THEN 1
+	        ELSE login_attempts.failures + 1
+	    END,
+	    last_failure_at = CURRENT_TIMESTAMP
+	RETURNING failures
+	`
+	var failures int
+	err := r.pool.QueryRow(context.Background(), query, key, window.Seconds()).Scan(&failures)
+	return failures, err
+}
+
+// LockLogin blocks login attempts for a throttle key for the given duration
+func (r *Repository) LockLogin(key string, d time.Duration) error {
+	query := `
+	UPDATE login_attempts SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $2)
+	WHERE key = $1
+	`
+	_, err := r.pool.Exec(context.Background(), query, key, d.Seconds())
+	return err
+}
+
+// ClearLoginAttempts removes the failure counter and any lockout of a throttle key
+func (r *Repository) ClearLoginAttempts(key string) error {
+	_, err := r.pool.Exec(context.Background(), `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
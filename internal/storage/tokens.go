@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrRefreshTokenExpired is returned when the presented refresh token is past its expiry
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+)
+
+// CreateRefreshToken stores the hash of a new refresh token in the given token family
+func (r *Repository) CreateRefreshToken(userID int, tokenHash, familyID string, ttl time.Duration) error {
+	query := `
+	INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+	`
+	_, err := r.pool.Exec(context.Background(), query, userID, tokenHash, familyID, ttl.Seconds())
+	return err
+}
+
+// RotateRefreshToken atomically revokes the presented refresh token and stores its
+// replacement in the same family. Presenting a token that was already revoked
+// revokes the whole family and returns ErrRefreshTokenReused.
+// It returns the owning user ID and the family ID.
+func (r *Repository) RotateRefreshToken(tokenHash, newHash string, ttl time.Duration) (int, string, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	SELECT id, user_id, family_id, revoked_at IS NOT NULL, expires_at <= CURRENT_TIMESTAMP
+	FROM refresh_tokens
+	WHERE token_hash = $1
+	FOR UPDATE
+	`
+
+	var id, userID int
+	var familyID string
+	var revoked, expired bool
+	err = tx.QueryRow(ctx, query, tokenHash).Scan(&id, &userID, &familyID, &revoked, &expired)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if revoked {
+		revokeQuery := `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE family_id = $1 AND revoked_at IS NULL
+		`
+		if _, err := tx.Exec(ctx, revokeQuery, familyID); err != nil {
+			return 0, "", err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return 0, "", err
+		}
+		return 0, "", ErrRefreshTokenReused
+	}
+
+	if expired {
+		return 0, "", ErrRefreshTokenExpired
+	}
+
+	insertQuery := `
+	INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+	RETURNING id
+	`
+	var newID int
+	err = tx.QueryRow(ctx, insertQuery, userID, newHash, familyID, ttl.Seconds()).Scan(&newID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	updateQuery := `
+	UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP, replaced_by = $2
+	WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, updateQuery, id, newID); err != nil {
+		return 0, "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, "", err
+	}
+
+	return userID, familyID, nil
+}
+
+// RevokeRefreshTokenFamily revokes every active refresh token in a family
+func (r *Repository) RevokeRefreshTokenFamily(familyID string) error {
+	query := `
+	UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+	WHERE family_id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.pool.Exec(context.Background(), query, familyID)
+	return err
+}
+
+// RevokeRefreshTokenFamilyByToken revokes the family of the given refresh token
+// if it belongs to the user. It returns pgx.ErrNoRows when no such token exists.
+func (r *Repository) RevokeRefreshTokenFamilyByToken(userID int, tokenHash string) error {
+	var familyID string
+	query := `SELECT family_id FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2`
+	err := r.pool.QueryRow(context.Background(), query, tokenHash, userID).Scan(&familyID)
+	if err != nil {
+		return err
+	}
+	return r.RevokeRefreshTokenFamily(familyID)
+}
+
+// RevokeToken adds an access token ID to the denylist until the token expires.
+// Entries for tokens that have already expired are pruned on the way.
+func (r *Repository) RevokeToken(jti string, expiresAt time.Time) error {
+	ctx := context.Background()
+
+	query := `
+	INSERT INTO revoked_tokens (jti, expires_at)
+	VALUES ($1, to_timestamp($2))
+	ON CONFLICT (jti) DO NOTHING
+	`
+	if _, err := r.pool.Exec(ctx, query, jti, expiresAt.Unix()); err != nil {
+		return err
+	}
+
+	_, err := r.pool.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP`)
+	return err
+}
+
+// IsTokenRevoked reports whether an access token ID is on the denylist
+func (r *Repository) IsTokenRevoked(jti string) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+	err := r.pool.QueryRow(context.Background(), query, jti).Scan(&revoked)
+	return revoked, err
+}
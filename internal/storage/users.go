@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"university/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListUsers returns a page of users with their roles, optionally filtered by an
+// email substring, a role name and the active flag. Sortable by id, email and
+// created_at.
+func (r *Repository) ListUsers(filter *model.UserFilter, params *model.ListParams) (*model.Page[model.UserResponse], error) {
+	columns := `u.id, u.email, u.is_active, u.created_at, u.email_verified_at,
+	       COALESCE((
+	           SELECT array_agg(r.name ORDER BY r.name)
+	           FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+	           WHERE ur.user_id = u.id
+	       ), '{}')`
+
+	var q listQuery
+	if filter.Query != "" {
+		q.where("u.email ILIKE '%' || ? || '%'", filter.Query)
+	}
+	if filter.Active != nil {
+		q.where("u.is_active = ?", *filter.Active)
+	}
+	if filter.Role != "" {
+		q.where(`EXISTS (
+		SELECT 1 FROM user_roles fur JOIN roles fr ON fr.id = fur.role_id
+		WHERE fur.user_id = u.id AND fr.name = ?)`, filter.Role)
+	}
+
+	order, err := orderBy(params.Sort, map[string]string{
+		"id":         "u.id",
+		"email":      "u.email",
+		"created_at": "u.created_at",
+	}, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return queryPage(r, columns, `users u`, &q, order, params, func(rows pgx.Rows, user *model.UserResponse) error {
+		return rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.EmailVerifiedAt,
+			&user.Roles,
+		)
+	})
+}
+
+// SetUserActive activates or deactivates a user. Deactivation also revokes all of
+// the user's refresh tokens. It returns pgx.ErrNoRows when the user does not exist.
+func (r *Repository) SetUserActive(userID int, active bool) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, `UPDATE users SET is_active = $1 WHERE id = $2 RETURNING id`, active, userID).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	if !active {
+		revokeQuery := `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND revoked_at IS NULL
+		`
+		if _, err := tx.Exec(ctx, revokeQuery, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GrantUserRole assigns a role to a user. Granting a role the user already has is a no-op.
+func (r *Repository) GrantUserRole(userID int, role string) error {
+	query := `
+	INSERT INTO user_roles (user_id, role_id)
+	SELECT $1, id FROM roles WHERE name = $2
+	ON CONFLICT DO NOTHING
+	`
+	_, err := r.pool.Exec(context.Background(), query, userID, role)
+	return err
+}
+
+// RevokeUserRole removes a role from a user
+func (r *Repository) RevokeUserRole(userID int, role string) error {
+	query := `
+	DELETE FROM user_roles
+	WHERE user_id = $1 AND role_id = (SELECT id FROM roles WHERE name = $2)
+	`
+	_, err := r.pool.Exec(context.Background(), query, userID, role)
+	return err
+}
+
+// InvalidatePassword clears a user's password hash so the current password stops
+// working, and revokes all of the user's refresh tokens
+func (r *Repository) InvalidatePassword(userID int) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET password_hash = '' WHERE id = $1`, userID); err != nil {
+		return err
+	}
+
+	revokeQuery := `
+	UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, revokeQuery, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// CreateEmailVerificationToken stores the hash of a new email verification token
+func (r *Repository) CreateEmailVerificationToken(userID int, tokenHash string, ttl time.Duration) error {
+	query := `
+	INSERT INTO email_verification_tokens (user_id, token_hash, expires_at)
+	VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3))
+	`
+	_, err := r.pool.Exec(context.Background(), query, userID, tokenHash, ttl.Seconds())
+	return err
+}
+
+// VerifyEmail consumes a valid verification token and marks the owner's email as
+// verified. It returns pgx.ErrNoRows when the token is unknown, used or expired.
+func (r *Repository) VerifyEmail(tokenHash string) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	SELECT user_id FROM email_verification_tokens
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	FOR UPDATE
+	`
+	var userID int
+	if err := tx.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		return 0, err
+	}
+
+	verifyQuery := `
+	UPDATE users SET email_verified_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND email_verified_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, verifyQuery, userID); err != nil {
+		return 0, err
+	}
+
+	usedQuery := `
+	UPDATE email_verification_tokens SET used_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND used_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, usedQuery, userID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}